@@ -0,0 +1,22 @@
+data "crane_image_config" "app" {
+  reference = "registry.example.com/team/app:1.4.2"
+  platform  = "linux/arm64"
+}
+
+# Derive the container definition of an ECS task from the image.
+locals {
+  container_definition = {
+    name             = "app"
+    image            = data.crane_image_config.app.reference
+    entryPoint       = data.crane_image_config.app.entrypoint
+    command          = data.crane_image_config.app.cmd
+    workingDirectory = data.crane_image_config.app.working_dir
+    user             = data.crane_image_config.app.user
+    environment = [
+      for variable in coalesce(data.crane_image_config.app.env, []) : {
+        name  = split("=", variable)[0]
+        value = join("=", slice(split("=", variable), 1, length(split("=", variable))))
+      }
+    ]
+  }
+}
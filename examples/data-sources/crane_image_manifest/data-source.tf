@@ -0,0 +1,8 @@
+data "crane_image_manifest" "example" {
+  reference = "registry.example.com/team/app@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+}
+
+locals {
+  image_size     = sum(concat([data.crane_image_manifest.example.config.size], [for layer in data.crane_image_manifest.example.layers : layer.size]))
+  foreign_layers = [for layer in data.crane_image_manifest.example.layers : layer.digest if length(coalesce(layer.urls, [])) > 0]
+}
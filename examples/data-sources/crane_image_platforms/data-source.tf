@@ -0,0 +1,16 @@
+data "crane_image_platforms" "upstream" {
+  reference = "docker.io/library/nginx:stable"
+}
+
+# Fail the plan when the upstream image drops a platform we deploy.
+resource "crane_image" "nginx" {
+  source      = data.crane_image_platforms.upstream.reference
+  destination = "registry.example.com/mirror/nginx:stable"
+
+  lifecycle {
+    precondition {
+      condition     = contains([for p in data.crane_image_platforms.upstream.platforms : p.platform], "linux/arm64")
+      error_message = "The upstream image no longer provides linux/arm64."
+    }
+  }
+}
@@ -1 +1,19 @@
-provider "crane" {}
+provider "crane" {
+  registry_auth {
+    address  = "ghcr.io"
+    username = "my-user"
+    password = var.ghcr_token
+  }
+
+  registry_auth {
+    address = "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+    ecr {
+      assume_role_arn = "arn:aws:iam::123456789012:role/image-publisher"
+    }
+  }
+
+  mirror {
+    registry  = "docker.io"
+    endpoints = ["mirror.example.com/docker-hub"]
+  }
+}
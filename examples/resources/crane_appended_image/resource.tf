@@ -0,0 +1,18 @@
+data "crane_digest" "base" {
+  reference = "nginx:1.27"
+}
+
+# Add a static site and its configuration to nginx
+resource "crane_appended_image" "site" {
+  base = "nginx@${data.crane_digest.base.digest}"
+  layers = [
+    "${path.module}/public",
+    "${path.module}/config.tar.gz",
+  ]
+  destination = "my-registry.local/site:latest"
+}
+
+# Deploy the exact image that was pushed
+output "image" {
+  value = crane_appended_image.site.pinned_reference
+}
@@ -0,0 +1,6 @@
+import {
+  to = crane_image.example
+  identity = {
+    destination = "registry.example.com/app:1.4.2"
+  }
+}
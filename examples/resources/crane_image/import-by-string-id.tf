@@ -0,0 +1,20 @@
+# Adopt a set of existing tags, copied from their upstream sources.
+locals {
+  images = {
+    "registry.example.com/nginx:1.27" = "docker.io/library/nginx:1.27"
+    "registry.example.com/redis:7.4"  = "docker.io/library/redis:7.4"
+  }
+}
+
+import {
+  for_each = local.images
+  to       = crane_image.mirror[each.key]
+  id       = "${each.key},${each.value}"
+}
+
+resource "crane_image" "mirror" {
+  for_each = local.images
+
+  source      = each.value
+  destination = each.key
+}
@@ -0,0 +1,9 @@
+# Import an image by its destination. The source is taken from configuration on the next apply.
+terraform import crane_image.example registry.example.com/app:1.4.2
+
+# Import an image along with its source, and optionally its platform, so that the next plan is empty.
+terraform import crane_image.example registry.example.com/app:1.4.2,docker.io/library/nginx:1.27
+terraform import crane_image.example registry.example.com/app:1.4.2,docker.io/library/nginx:1.27,linux/amd64
+
+# Import an image pinned by digest.
+terraform import crane_image.example registry.example.com/app@sha256:4c3b4f6c7a0e9e1b9c1f7d5c2a8e6b0d3f9a1c5e7b2d4f6a8c0e2b4d6f8a0c2e
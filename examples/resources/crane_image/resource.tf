@@ -25,3 +25,69 @@ resource "crane_image" "mutable_file" {
   source_digest = filemd5("path/to/local/image.tar")
   destination   = "my-registry.local/nginx:stable"
 }
+
+# Replicate an image to several registries, reading the source only once
+resource "crane_image" "replicated" {
+  source       = "alpine:3.22.2"
+  destinations = [
+    "123456789012.dkr.ecr.us-east-1.amazonaws.com/alpine:3.22.2",
+    "123456789012.dkr.ecr.eu-west-1.amazonaws.com/alpine:3.22.2",
+    "harbor.example.com/library/alpine:3.22.2",
+  ]
+}
+
+# Publish a release under several tags with a single push
+resource "crane_image" "release" {
+  source          = "path/to/local/app-1.4.2.tar"
+  destination     = "my-registry.local/app:1.4.2"
+  additional_tags = ["1.4", "latest"]
+}
+
+# Stamp provenance, ownership and a non-root user on a vendor image before it lands in our registry
+resource "crane_image" "vendor" {
+  source      = "nginx:1.27"
+  destination = "my-registry.local/vendor/nginx:1.27"
+
+  mutate {
+    labels = {
+      "org.opencontainers.image.source" = "https://github.com/nginx/docker-nginx"
+      "com.example.owner"               = "platform-team"
+    }
+    user = "nginx"
+  }
+}
+
+# Promote a signed image to production along with its signatures, SBOMs and attestations
+resource "crane_image" "promote" {
+  source         = "my-registry.local/staging/app:1.4.2"
+  destination    = "prod-registry.example.com/app:1.4.2"
+  copy_referrers = true
+}
+
+# Only promote images signed by the build system
+resource "crane_image" "verified" {
+  source      = "my-registry.local/staging/app:1.4.2"
+  destination = "prod-registry.example.com/app:1.4.2"
+
+  verify {
+    public_keys = [file("${path.module}/cosign.pub")]
+  }
+}
+
+# Produce an air-gapped bundle in an OCI image layout directory, keeping every platform
+resource "crane_image" "bundle" {
+  source      = "alpine:3.22.2"
+  destination = "oci-layout://${path.module}/bundle:alpine-3.22.2"
+}
+
+# Consume the bundle on the other side of the air gap, copying only the platform needed
+resource "crane_image" "from_bundle" {
+  source      = "oci-layout://path/to/bundle:alpine-3.22.2"
+  platform    = "linux/amd64"
+  destination = "my-registry.local/alpine:3.22.2"
+}
+
+# Hand downstream deployments an immutable reference (registry/repo@sha256:...)
+output "alpine_image" {
+  value = crane_image.example.pinned_reference
+}
@@ -0,0 +1,22 @@
+# Combine the images built by separate CI runners into a multi-architecture image
+resource "crane_image_index" "app" {
+  destination = "my-registry.local/app:${var.git_sha}"
+
+  manifests = [
+    {
+      reference = "my-registry.local/app:${var.git_sha}-amd64"
+    },
+    {
+      reference = "my-registry.local/app:${var.git_sha}-arm64"
+      platform  = "linux/arm64/v8"
+    },
+  ]
+
+  annotations = {
+    "org.opencontainers.image.revision" = var.git_sha
+  }
+}
+
+variable "git_sha" {
+  type = string
+}
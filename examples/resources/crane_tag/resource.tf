@@ -0,0 +1,9 @@
+data "crane_digest" "staging" {
+  reference = "my-registry.local/app:staging"
+}
+
+# Promote the image currently tagged as staging to prod without copying any layers
+resource "crane_tag" "promote" {
+  image = "my-registry.local/app@${data.crane_digest.staging.digest}"
+  tags  = ["prod", "1.4.2"]
+}
@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// layerTime is the modification time of every file in appended layers, so that layers only
+// change when file contents or metadata other than times do.
+var layerTime = time.Unix(0, 0).UTC()
+
+// appendedLayer reads path, a tar file (optionally gzip compressed) or a directory, as a layer
+// of mediaType. Directory contents are placed at the root of the image filesystem. File
+// times are reset to layerTime, and directories are walked in lexical order.
+func appendedLayer(path string, mediaType types.MediaType) (v1.Layer, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layer '%s': %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if info.IsDir() {
+		err = writeDirectory(tw, path)
+	} else {
+		err = writeTarFile(tw, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layer '%s': %w", path, err)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read layer '%s': %w", path, err)
+	}
+
+	content := buf.Bytes()
+	return tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}, tarball.WithMediaType(mediaType))
+}
+
+// writeDirectory writes the contents of dir to tw.
+func writeDirectory(tw *tar.Writer, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		// Only the contents and permissions of files are kept, so that the layer does not
+		// depend on the machine it is built on.
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
+		normalizeHeader(header)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// writeTarFile copies the entries of the tar file at path to tw.
+func writeTarFile(tw *tar.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	gz, err := gzip.NewReader(f)
+	switch {
+	case err == nil:
+		defer gz.Close()
+		r = gz
+	case errors.Is(err, gzip.ErrHeader), errors.Is(err, io.EOF):
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		normalizeHeader(header)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// normalizeHeader resets the times of header to layerTime.
+func normalizeHeader(header *tar.Header) {
+	header.ModTime = layerTime
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Format = tar.FormatPAX
+	delete(header.PAXRecords, "mtime")
+	delete(header.PAXRecords, "atime")
+	delete(header.PAXRecords, "ctime")
+}
+
+// appendLayers appends the layers read from paths to base, in order. Layers get the media
+// type matching the manifest of base.
+func appendLayers(base v1.Image, paths []string) (v1.Image, []string, error) {
+	mediaType, err := base.MediaType()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read base image media type: %w", err)
+	}
+	layerType := types.DockerLayer
+	if mediaType == types.OCIManifestSchema1 {
+		layerType = types.OCILayer
+	}
+
+	addenda := make([]mutate.Addendum, 0, len(paths))
+	diffIDs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		layer, err := appendedLayer(path, layerType)
+		if err != nil {
+			return nil, nil, err
+		}
+		diffID, err := layer.DiffID()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read layer '%s': %w", path, err)
+		}
+		diffIDs = append(diffIDs, diffID.String())
+		addenda = append(addenda, mutate.Addendum{
+			Layer:     layer,
+			MediaType: layerType,
+			History:   v1.History{CreatedBy: "terraform-provider-crane append", Created: v1.Time{Time: layerTime}},
+		})
+	}
+
+	img, err := mutate.Append(base, addenda...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to append layers: %w", err)
+	}
+	return img, diffIDs, nil
+}
+
+// layerDiffIDs returns the diff IDs of the layers read from paths, which identify their
+// uncompressed contents.
+func layerDiffIDs(paths []string) ([]string, error) {
+	diffIDs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		layer, err := appendedLayer(path, types.OCILayer)
+		if err != nil {
+			return nil, err
+		}
+		diffID, err := layer.DiffID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layer '%s': %w", path, err)
+		}
+		diffIDs = append(diffIDs, diffID.String())
+	}
+	return diffIDs, nil
+}
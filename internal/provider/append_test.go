@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestAppendedLayerDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "etc", "app"), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	file := filepath.Join(dir, "etc", "app", "config.yaml")
+	if err := os.WriteFile(file, []byte("port: 8080\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	first, err := layerDiffIDs([]string{dir})
+	if err != nil {
+		t.Fatalf("layerDiffIDs() error = %v", err)
+	}
+
+	// Times are not part of the layer.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatalf("failed to change file times: %v", err)
+	}
+	second, err := layerDiffIDs([]string{dir})
+	if err != nil {
+		t.Fatalf("layerDiffIDs() error = %v", err)
+	}
+	if !slices.Equal(first, second) {
+		t.Errorf("layerDiffIDs() = %v after touching a file, want %v", second, first)
+	}
+
+	if err := os.WriteFile(file, []byte("port: 9090\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	third, err := layerDiffIDs([]string{dir})
+	if err != nil {
+		t.Fatalf("layerDiffIDs() error = %v", err)
+	}
+	if slices.Equal(first, third) {
+		t.Errorf("layerDiffIDs() = %v after changing a file, want it to change", third)
+	}
+
+	layer, err := appendedLayer(dir, types.OCILayer)
+	if err != nil {
+		t.Fatalf("appendedLayer() error = %v", err)
+	}
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		t.Fatalf("failed to read layer: %v", err)
+	}
+	defer rc.Close()
+	var names []string
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read layer entry: %v", err)
+		}
+		if !header.ModTime.Equal(layerTime) || header.Uid != 0 || header.Gid != 0 {
+			t.Errorf("entry %s has time %s and owner %d:%d, want %s and 0:0", header.Name, header.ModTime, header.Uid, header.Gid, layerTime)
+		}
+		names = append(names, header.Name)
+	}
+	if want := []string{"etc/", "etc/app/", "etc/app/config.yaml"}; !slices.Equal(names, want) {
+		t.Errorf("layer entries = %v, want %v", names, want)
+	}
+}
+
+func TestAppendedLayerTarFile(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	content := []byte("#!/bin/sh\n")
+	if err := tw.WriteHeader(&tar.Header{Name: "bin/app", Mode: 0o755, Size: int64(len(content)), ModTime: time.Now()}); err != nil {
+		t.Fatalf("failed to write tar header: %v", err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatalf("failed to write tar content: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "layer.tar")
+	if err := os.WriteFile(plain, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("failed to write tar: %v", err)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(buf.Bytes()); err != nil {
+		t.Fatalf("failed to compress tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("failed to compress tar: %v", err)
+	}
+	gzipped := filepath.Join(dir, "layer.tar.gz")
+	if err := os.WriteFile(gzipped, compressed.Bytes(), 0o644); err != nil {
+		t.Fatalf("failed to write tar: %v", err)
+	}
+
+	diffIDs, err := layerDiffIDs([]string{plain, gzipped})
+	if err != nil {
+		t.Fatalf("layerDiffIDs() error = %v", err)
+	}
+	if diffIDs[0] != diffIDs[1] {
+		t.Errorf("layerDiffIDs() = %v, want compressed and uncompressed tar files to match", diffIDs)
+	}
+
+	if _, err := layerDiffIDs([]string{filepath.Join(dir, "missing.tar")}); err == nil {
+		t.Error("layerDiffIDs() of a missing file succeeded, want an error")
+	}
+}
+
+func TestAppendLayersMediaType(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		manifest  types.MediaType
+		layerType types.MediaType
+	}{
+		{name: "docker", manifest: types.DockerManifestSchema2, layerType: types.DockerLayer},
+		{name: "oci", manifest: types.OCIManifestSchema1, layerType: types.OCILayer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := mutate.MediaType(empty.Image, tt.manifest)
+			img, diffIDs, err := appendLayers(base, []string{dir})
+			if err != nil {
+				t.Fatalf("appendLayers() error = %v", err)
+			}
+			if len(diffIDs) != 1 {
+				t.Fatalf("appendLayers() diff IDs = %v, want 1", diffIDs)
+			}
+			layers, err := img.Layers()
+			if err != nil {
+				t.Fatalf("failed to read layers: %v", err)
+			}
+			if len(layers) != 1 {
+				t.Fatalf("image has %d layers, want 1", len(layers))
+			}
+			mediaType, err := layers[0].MediaType()
+			if err != nil {
+				t.Fatalf("failed to read layer media type: %v", err)
+			}
+			if mediaType != tt.layerType {
+				t.Errorf("layer media type = %s, want %s", mediaType, tt.layerType)
+			}
+
+			again, _, err := appendLayers(base, []string{dir})
+			if err != nil {
+				t.Fatalf("appendLayers() error = %v", err)
+			}
+			digest, err := img.Digest()
+			if err != nil {
+				t.Fatalf("failed to read digest: %v", err)
+			}
+			againDigest, err := again.Digest()
+			if err != nil {
+				t.Fatalf("failed to read digest: %v", err)
+			}
+			if digest != againDigest {
+				t.Errorf("appendLayers() digest = %s, then %s, want it to be deterministic", digest, againDigest)
+			}
+		})
+	}
+}
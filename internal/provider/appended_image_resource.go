@@ -0,0 +1,349 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &AppendedImageResource{}
+var _ resource.ResourceWithConfigure = &AppendedImageResource{}
+var _ resource.ResourceWithModifyPlan = &AppendedImageResource{}
+
+func NewAppendedImageResource() resource.Resource {
+	return &AppendedImageResource{}
+}
+
+// AppendedImageResource defines the resource implementation.
+type AppendedImageResource struct {
+	client *registryClient
+}
+
+// AppendedImageResourceModel describes the resource data model.
+type AppendedImageResourceModel struct {
+	Id              types.String `tfsdk:"id"`
+	Base            types.String `tfsdk:"base"`
+	Layers          types.List   `tfsdk:"layers"`
+	Destination     types.String `tfsdk:"destination"`
+	Platform        types.String `tfsdk:"platform"`
+	Entrypoint      types.List   `tfsdk:"entrypoint"`
+	LayerDigests    types.List   `tfsdk:"layer_digests"`
+	Digest          types.String `tfsdk:"digest"`
+	PinnedReference types.String `tfsdk:"pinned_reference"`
+}
+
+func (r *AppendedImageResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_appended_image"
+}
+
+func (r *AppendedImageResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Build an image by appending local files to a base image and push it, equivalent to ` + "`crane append`" + `.
+
+Layers are built with every file time reset to the Unix epoch, so the image digest only depends on the base image and the contents of the layers. Changes to the layer files are detected when planning, through ` + "`layer_digests`" + `. Destroying the resource does not delete the image from the repository.`,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Equivalent to `destination`.",
+			},
+			"base": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The remote image to append layers to. Pin it by digest (e.g. with the `crane_digest` data source) to pick up updates to a mutable tag.",
+			},
+			"layers": schema.ListAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Paths to tar files, optionally gzip compressed, or directories to append as layers, in order. The contents of a directory are placed at the root of the image filesystem, owned by root.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"destination": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The destination to push the image to (`registry/repo` or `registry/repo:tag`).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"platform": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "If base is a multi-architecture image, the platform to append layers to in the form os/arch[/variant][:osversion] (e.g. linux/arm64). (default linux/amd64)",
+			},
+			"entrypoint": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The entrypoint to set on the image. (default the entrypoint of `base`)",
+			},
+			"layer_digests": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The digests of the uncompressed contents of `layers`, computed when planning.",
+			},
+			"digest": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The digest of the pushed image.",
+			},
+			"pinned_reference": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The immutable reference of the pushed image pinned by digest (`registry/repo@sha256:...`).",
+			},
+		},
+	}
+}
+
+func (r *AppendedImageResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*registryClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *registryClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan reads the layers so that changes to their contents are planned as an update.
+func (r *AppendedImageResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan AppendedImageResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.Layers.IsUnknown() {
+		return
+	}
+
+	var elements []types.String
+	resp.Diagnostics.Append(plan.Layers.ElementsAs(ctx, &elements, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	paths := make([]string, 0, len(elements))
+	for _, element := range elements {
+		if element.IsUnknown() {
+			// A path is not known yet, so the layers are read when applying.
+			return
+		}
+		paths = append(paths, element.ValueString())
+	}
+	diffIDs, err := layerDiffIDs(paths)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("layers"), "Error reading layer", err.Error())
+		return
+	}
+	layerDigests, diags := types.ListValueFrom(ctx, types.StringType, diffIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("layer_digests"), layerDigests)...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var state AppendedImageResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !state.LayerDigests.Equal(layerDigests) {
+		tflog.Debug(ctx, "Layer contents changed, the image will be rebuilt")
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("digest"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("pinned_reference"), types.StringUnknown())...)
+	}
+}
+
+func (r *AppendedImageResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AppendedImageResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.build(ctx, &data, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AppendedImageResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AppendedImageResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	destination := data.Id.ValueString()
+	digest, err := crane.Digest(destination, r.client.options...)
+	if err != nil {
+		var remoteErr *transport.Error
+		if errors.As(err, &remoteErr) && remoteErr.StatusCode == http.StatusNotFound {
+			resp.Diagnostics.AddWarning(
+				"Image Not Found",
+				fmt.Sprintf("Image '%s' not found in the registry. It may have been deleted or never pushed.", destination),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error reading image digest",
+			fmt.Sprintf("Unable to read image digest for '%s': %s", destination, err),
+		)
+		return
+	}
+
+	if digest != data.Digest.ValueString() {
+		// The destination was overwritten outside of Terraform. Forgetting the layers makes
+		// the next plan rebuild and push the image again.
+		tflog.Debug(ctx, fmt.Sprintf("Image '%s' now has digest '%s'", destination, digest))
+		data.LayerDigests = types.ListNull(types.StringType)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AppendedImageResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data AppendedImageResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.build(ctx, &data, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AppendedImageResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// build appends the layers of data to its base image, pushes the result to its destination
+// and records the computed attributes in data. When failOnConflict is set, a destination
+// already holding a different image is reported as a conflict instead of being overwritten.
+func (r *AppendedImageResource) build(ctx context.Context, data *AppendedImageResourceModel, failOnConflict bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	craneOpts, err := setPlatform(r.client.options, data.Platform)
+	if err != nil {
+		diags.AddError(
+			"Error parsing platform",
+			fmt.Sprintf("Unable to parse platform '%s': %s", data.Platform.ValueString(), err),
+		)
+		return diags
+	}
+
+	var paths []string
+	diags.Append(data.Layers.ElementsAs(ctx, &paths, false)...)
+	var entrypoint []string
+	if !data.Entrypoint.IsNull() {
+		diags.Append(data.Entrypoint.ElementsAs(ctx, &entrypoint, false)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	base := data.Base.ValueString()
+	destination := data.Destination.ValueString()
+	resolvedBase, err := r.client.resolveReference(ctx, base, craneOpts)
+	if err != nil {
+		diags.AddError(
+			"Error reading base image",
+			fmt.Sprintf("Unable to read base image '%s': %s", base, err),
+		)
+		return diags
+	}
+	o := crane.GetOptions(craneOpts...)
+
+	baseRef, err := name.ParseReference(resolvedBase, o.Name...)
+	if err != nil {
+		diags.AddError(
+			"Error reading base image",
+			fmt.Sprintf("Unable to parse base image reference '%s': %s", base, err),
+		)
+		return diags
+	}
+	baseImage, err := remote.Image(baseRef, o.Remote...)
+	if err != nil {
+		diags.AddError(
+			"Error reading base image",
+			fmt.Sprintf("Unable to read base image '%s': %s", base, err),
+		)
+		return diags
+	}
+
+	img, diffIDs, err := appendLayers(baseImage, paths)
+	if err != nil {
+		diags.AddAttributeError(path.Root("layers"), "Error reading layer", err.Error())
+		return diags
+	}
+	if entrypoint != nil {
+		img, err = mutateSingleImage(img, imageMutation{entrypoint: entrypoint})
+		if err != nil {
+			diags.AddError("Error setting entrypoint", err.Error())
+			return diags
+		}
+	}
+	hash, err := img.Digest()
+	if err != nil {
+		diags.AddError(
+			"Error building image",
+			fmt.Sprintf("Unable to compute the digest of the image: %s", err),
+		)
+		return diags
+	}
+	digest := hash.String()
+
+	onConflict := onConflictOverwrite
+	if failOnConflict {
+		onConflict = onConflictFail
+	}
+	if _, d := pushDestination(ctx, img, base, digest, destination, craneOpts, onConflict); d != nil {
+		diags.Append(d)
+		return diags
+	}
+
+	layerDigests, d := types.ListValueFrom(ctx, types.StringType, diffIDs)
+	diags.Append(d...)
+	data.Id = types.StringValue(destination)
+	data.Digest = types.StringValue(digest)
+	data.PinnedReference = pinnedReference(destination, digest, o)
+	data.LayerDigests = layerDigests
+	return diags
+}
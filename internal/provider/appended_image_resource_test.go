@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	testutils "github.com/adam-tylr/terraform-provider-crane/testing"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccAppendedImageResource(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	tags := testutils.CopyImagesToRepository(t, repo)
+
+	base := fmt.Sprintf("%s:%s", repo, tags[0])
+	destination := fmt.Sprintf("%s:appended", repo)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "index.html")
+	if err := os.WriteFile(file, []byte("<h1>v1</h1>\n"), 0o644); err != nil {
+		t.Fatalf("failed to write layer file: %v", err)
+	}
+	v1DiffIDs, err := layerDiffIDs([]string{dir})
+	if err != nil {
+		t.Fatalf("failed to compute layer digests: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccAppendedImage(base, dir, destination),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_appended_image.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(destination),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_appended_image.test",
+						tfjsonpath.New("layer_digests"),
+						knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact(v1DiffIDs[0])}),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_appended_image.test",
+						tfjsonpath.New("pinned_reference"),
+						knownvalue.StringRegexp(regexp.MustCompile(`@sha256:[0-9a-f]{64}$`)),
+					),
+				},
+				Check: func(*terraform.State) error {
+					cfg, err := crane.Config(destination)
+					if err != nil {
+						return err
+					}
+					if !regexp.MustCompile(`"Entrypoint":\["/bin/serve"\]`).Match(cfg) {
+						return fmt.Errorf("pushed config %s does not have the entrypoint", cfg)
+					}
+					return nil
+				},
+			},
+			// Changing a layer file rebuilds the image
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(file, []byte("<h1>v2</h1>\n"), 0o644); err != nil {
+						t.Fatalf("failed to write layer file: %v", err)
+					}
+				},
+				Config: testAccAppendedImage(base, dir, destination),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_appended_image.test",
+							plancheck.ResourceActionUpdate,
+						),
+						plancheck.ExpectUnknownValue(
+							"crane_appended_image.test",
+							tfjsonpath.New("digest"),
+						),
+					},
+				},
+			},
+			// Unchanged layers plan no changes
+			{
+				Config:   testAccAppendedImage(base, dir, destination),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccAppendedImage(base string, layer string, destination string) string {
+	return fmt.Sprintf(`
+resource "crane_appended_image" "test" {
+  base        = %q
+  layers      = [%q]
+  destination = %q
+  entrypoint  = ["/bin/serve"]
+}
+`, base, layer, destination)
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// registryClient holds the provider configuration shared by all resources and data sources.
+type registryClient struct {
+	options []crane.Option
+	// mirrors maps an upstream registry host to the mirror endpoints to try, in order,
+	// before reading from the upstream registry.
+	mirrors map[string][]string
+}
+
+// mirrorModel describes a mirror block in the provider configuration.
+type mirrorModel struct {
+	Registry  types.String `tfsdk:"registry"`
+	Endpoints types.List   `tfsdk:"endpoints"`
+}
+
+// newMirrors builds the upstream registry to mirror endpoints mapping from the configured
+// mirror blocks.
+func newMirrors(ctx context.Context, models []mirrorModel) (map[string][]string, error) {
+	mirrors := map[string][]string{}
+	for _, m := range models {
+		registry := m.Registry.ValueString()
+		host, err := registryHost(registry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry %q: %w", registry, err)
+		}
+		if _, ok := mirrors[host]; ok {
+			return nil, fmt.Errorf("duplicate mirror block for registry %q", host)
+		}
+
+		var endpoints []string
+		if diags := m.Endpoints.ElementsAs(ctx, &endpoints, false); diags.HasError() {
+			return nil, fmt.Errorf("reading endpoints for registry %q", registry)
+		}
+		for i, endpoint := range endpoints {
+			endpoint = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://"), "/")
+			if _, err := name.NewRepository(endpoint + "/probe"); err != nil {
+				return nil, fmt.Errorf("invalid mirror endpoint %q: %w", endpoints[i], err)
+			}
+			endpoints[i] = endpoint
+		}
+		mirrors[host] = endpoints
+	}
+	return mirrors, nil
+}
+
+// resolveReference returns the location ref should be read from: the first mirror of its
+// registry that serves the image, or ref itself when no mirror does.
+func (c *registryClient) resolveReference(ctx context.Context, ref string, opts []crane.Option) (string, error) {
+	o := crane.GetOptions(opts...)
+	parsed, err := name.ParseReference(ref, o.Name...)
+	if err != nil {
+		return "", err
+	}
+
+	for _, endpoint := range c.mirrors[parsed.Context().RegistryStr()] {
+		candidate := mirrorReference(parsed, endpoint)
+		if _, err := crane.Head(candidate, opts...); err != nil {
+			tflog.Debug(ctx, fmt.Sprintf("Mirror '%s' cannot serve '%s', trying next: %s", endpoint, ref, err))
+			continue
+		}
+		tflog.Debug(ctx, fmt.Sprintf("Reading '%s' from mirror '%s'", ref, candidate))
+		return candidate, nil
+	}
+	return ref, nil
+}
+
+// resolveSource returns the location source is read from, applying the provider's registry
+// mirrors to remote references. Local tarballs and OCI image layouts are returned unchanged.
+func (c *registryClient) resolveSource(ctx context.Context, source string, opts []crane.Option) (string, error) {
+	if _, err := os.Stat(source); err == nil || isOCILayout(source) {
+		return source, nil
+	}
+	return c.resolveReference(ctx, source, opts)
+}
+
+// mirrorReference rewrites ref to point at the same repository and identifier on endpoint,
+// which may include a path prefix (e.g. `mirror.example.com/docker-hub`).
+func mirrorReference(ref name.Reference, endpoint string) string {
+	repository := fmt.Sprintf("%s/%s", endpoint, ref.Context().RepositoryStr())
+	if digest, ok := ref.(name.Digest); ok {
+		return fmt.Sprintf("%s@%s", repository, digest.DigestStr())
+	}
+	return fmt.Sprintf("%s:%s", repository, ref.Identifier())
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestMirrorReference(t *testing.T) {
+	cases := map[string]string{
+		"alpine":                               "mirror.example.com/library/alpine:latest",
+		"nginx:1.27":                           "mirror.example.com/library/nginx:1.27",
+		"ghcr.io/org/app@sha256:" + zeroDigest: "mirror.example.com/org/app@sha256:" + zeroDigest,
+	}
+	for ref, expected := range cases {
+		parsed, err := name.ParseReference(ref)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", ref, err)
+		}
+		if actual := mirrorReference(parsed, "mirror.example.com"); actual != expected {
+			t.Errorf("mirrorReference(%q) = %q, expected %q", ref, actual, expected)
+		}
+	}
+}
+
+func TestNewMirrors(t *testing.T) {
+	mirrors, err := newMirrors(t.Context(), []mirrorModel{
+		{
+			Registry: types.StringValue("docker.io"),
+			Endpoints: types.ListValueMust(types.StringType, []attr.Value{
+				types.StringValue("https://mirror.example.com/docker-hub/"),
+				types.StringValue("backup.example.com"),
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"mirror.example.com/docker-hub", "backup.example.com"}
+	if fmt.Sprint(mirrors["index.docker.io"]) != fmt.Sprint(expected) {
+		t.Errorf("unexpected mirrors: %v", mirrors)
+	}
+}
+
+func TestResolveReference(t *testing.T) {
+	upstream := httptest.NewServer(testRegistry())
+	defer upstream.Close()
+	mirror := httptest.NewServer(testRegistry())
+	defer mirror.Close()
+	upstreamHost := strings.TrimPrefix(upstream.URL, "http://")
+	mirrorHost := strings.TrimPrefix(mirror.URL, "http://")
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	for _, ref := range []string{upstreamHost + "/app:cached", upstreamHost + "/app:uncached", mirrorHost + "/cache/app:cached"} {
+		if err := crane.Push(img, ref); err != nil {
+			t.Fatalf("failed to push %q: %v", ref, err)
+		}
+	}
+
+	client := &registryClient{
+		mirrors: map[string][]string{
+			upstreamHost: {mirrorHost + "/missing", mirrorHost + "/cache"},
+		},
+	}
+	cases := map[string]string{
+		upstreamHost + "/app:cached":   mirrorHost + "/cache/app:cached",
+		upstreamHost + "/app:uncached": upstreamHost + "/app:uncached",
+	}
+	for ref, expected := range cases {
+		actual, err := client.resolveReference(t.Context(), ref, client.options)
+		if err != nil {
+			t.Fatalf("failed to resolve %q: %v", ref, err)
+		}
+		if actual != expected {
+			t.Errorf("resolveReference(%q) = %q, expected %q", ref, actual, expected)
+		}
+	}
+}
+
+const zeroDigest = "0000000000000000000000000000000000000000000000000000000000000000"
@@ -15,14 +15,15 @@ var _ datasource.DataSource = &DigestDataSource{}
 
 // DigestDataSource resolves the manifest digest for a reference.
 type DigestDataSource struct {
-	options []crane.Option
+	client *registryClient
 }
 
 // DigestDataSourceModel describes the data source model.
 type DigestDataSourceModel struct {
-	ID        types.String `tfsdk:"id"`
-	Reference types.String `tfsdk:"reference"`
-	Digest    types.String `tfsdk:"digest"`
+	ID                types.String `tfsdk:"id"`
+	Reference         types.String `tfsdk:"reference"`
+	ResolvedReference types.String `tfsdk:"resolved_reference"`
+	Digest            types.String `tfsdk:"digest"`
 }
 
 func NewDigestDataSource() datasource.DataSource {
@@ -45,6 +46,10 @@ func (d *DigestDataSource) Schema(ctx context.Context, req datasource.SchemaRequ
 				MarkdownDescription: "A tag or digest identifying the image to inspect (for example `registry/repository:tag`).",
 				Required:            true,
 			},
+			"resolved_reference": schema.StringAttribute{
+				MarkdownDescription: "The location the digest was actually read from. Differs from `reference` when the image was read through a provider `mirror`.",
+				Computed:            true,
+			},
 			"digest": schema.StringAttribute{
 				MarkdownDescription: "Content digest of the referenced image, such as `sha256:...`.",
 				Computed:            true,
@@ -58,17 +63,17 @@ func (d *DigestDataSource) Configure(ctx context.Context, req datasource.Configu
 		return
 	}
 
-	options, ok := req.ProviderData.([]crane.Option)
+	client, ok := req.ProviderData.(*registryClient)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *[]crane.Option, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *registryClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.options = options
+	d.client = client
 }
 
 func (d *DigestDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -80,16 +85,23 @@ func (d *DigestDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	}
 
 	ref := data.Reference.ValueString()
-	options := append([]crane.Option{}, d.options...)
+	options := append([]crane.Option{}, d.client.options...)
 	options = append(options, crane.WithContext(ctx))
 
-	digest, err := crane.Digest(ref, options...)
+	resolved, err := d.client.resolveReference(ctx, ref, options)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading digest for %q", ref), err.Error())
+		return
+	}
+
+	digest, err := crane.Digest(resolved, options...)
 	if err != nil {
 		resp.Diagnostics.AddError(fmt.Sprintf("reading digest for %q", ref), err.Error())
 		return
 	}
 
 	data.ID = types.StringValue(ref)
+	data.ResolvedReference = types.StringValue(resolved)
 	data.Digest = types.StringValue(digest)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
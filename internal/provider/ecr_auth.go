@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ecrTokenRefreshWindow is how long before expiry an ECR authorization token is renewed,
+// so that a token is never handed out just before it expires mid-operation.
+const ecrTokenRefreshWindow = 5 * time.Minute
+
+var ecrRegistryPattern = regexp.MustCompile(`^\d{12}\.dkr(?:-fips)?\.ecr\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// ecrAuthModel describes the ecr block of a registry_auth block.
+type ecrAuthModel struct {
+	Region        types.String `tfsdk:"region"`
+	Profile       types.String `tfsdk:"profile"`
+	AssumeRoleARN types.String `tfsdk:"assume_role_arn"`
+}
+
+// ecrAuthenticator exchanges AWS credentials for an ECR authorization token, caching the
+// token until shortly before it expires.
+type ecrAuthenticator struct {
+	registry string
+	region   string
+	profile  string
+	roleARN  string
+
+	mu        sync.Mutex
+	auth      *authn.AuthConfig
+	expiresAt time.Time
+}
+
+var _ authn.ContextAuthenticator = &ecrAuthenticator{}
+
+func newECRAuthenticator(registry string, m ecrAuthModel) (*ecrAuthenticator, error) {
+	region := m.Region.ValueString()
+	if region == "" {
+		match := ecrRegistryPattern.FindStringSubmatch(registry)
+		if match == nil {
+			return nil, fmt.Errorf("region must be set when %q is not an ECR registry host", registry)
+		}
+		region = match[1]
+	}
+
+	return &ecrAuthenticator{
+		registry: registry,
+		region:   region,
+		profile:  m.Profile.ValueString(),
+		roleARN:  m.AssumeRoleARN.ValueString(),
+	}, nil
+}
+
+func (a *ecrAuthenticator) Authorization() (*authn.AuthConfig, error) {
+	return a.AuthorizationContext(context.Background())
+}
+
+func (a *ecrAuthenticator) AuthorizationContext(ctx context.Context) (*authn.AuthConfig, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.auth != nil && time.Now().Add(ecrTokenRefreshWindow).Before(a.expiresAt) {
+		return a.auth, nil
+	}
+
+	auth, expiresAt, err := a.fetchToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting ECR authorization token for %q: %w", a.registry, err)
+	}
+	a.auth = auth
+	a.expiresAt = expiresAt
+	return auth, nil
+}
+
+func (a *ecrAuthenticator) fetchToken(ctx context.Context) (*authn.AuthConfig, time.Time, error) {
+	loadOpts := []func(*config.LoadOptions) error{config.WithRegion(a.region)}
+	if a.profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(a.profile))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+	if a.roleARN != "" {
+		cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), a.roleARN))
+	}
+
+	out, err := ecr.NewFromConfig(cfg).GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if len(out.AuthorizationData) == 0 || out.AuthorizationData[0].AuthorizationToken == nil {
+		return nil, time.Time{}, fmt.Errorf("no authorization data returned")
+	}
+
+	data := out.AuthorizationData[0]
+	decoded, err := base64.StdEncoding.DecodeString(*data.AuthorizationToken)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("decoding authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("malformed authorization token")
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	if data.ExpiresAt != nil {
+		expiresAt = *data.ExpiresAt
+	}
+	return &authn.AuthConfig{Username: username, Password: password}, expiresAt, nil
+}
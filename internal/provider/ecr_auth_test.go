@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestECRAuthenticatorRegion(t *testing.T) {
+	auth, err := newECRAuthenticator("123456789012.dkr.ecr.eu-west-2.amazonaws.com", ecrAuthModel{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth.region != "eu-west-2" {
+		t.Errorf("expected region eu-west-2, got %q", auth.region)
+	}
+
+	auth, err = newECRAuthenticator("registry.example.com", ecrAuthModel{Region: types.StringValue("us-east-1")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth.region != "us-east-1" {
+		t.Errorf("expected region us-east-1, got %q", auth.region)
+	}
+
+	if _, err := newECRAuthenticator("registry.example.com", ecrAuthModel{}); err == nil {
+		t.Error("expected an error for a non-ECR registry without a region")
+	}
+}
+
+func TestECRAuthenticatorCachesToken(t *testing.T) {
+	cached := &authn.AuthConfig{Username: "AWS", Password: "token"}
+	auth := &ecrAuthenticator{
+		registry:  "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+		region:    "us-east-1",
+		auth:      cached,
+		expiresAt: time.Now().Add(time.Hour),
+	}
+
+	cfg, err := auth.AuthorizationContext(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg != cached {
+		t.Errorf("expected the cached token to be reused, got %+v", cfg)
+	}
+}
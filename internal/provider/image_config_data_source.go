@@ -0,0 +1,262 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ImageConfigDataSource{}
+
+// ImageConfigDataSource reads the config of an image.
+type ImageConfigDataSource struct {
+	client *registryClient
+}
+
+// ImageConfigDataSourceModel describes the data source model.
+type ImageConfigDataSourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Reference         types.String `tfsdk:"reference"`
+	Platform          types.String `tfsdk:"platform"`
+	ResolvedReference types.String `tfsdk:"resolved_reference"`
+	Digest            types.String `tfsdk:"digest"`
+	ConfigDigest      types.String `tfsdk:"config_digest"`
+	OS                types.String `tfsdk:"os"`
+	Architecture      types.String `tfsdk:"architecture"`
+	Variant           types.String `tfsdk:"variant"`
+	Created           types.String `tfsdk:"created"`
+	Env               types.List   `tfsdk:"env"`
+	Entrypoint        types.List   `tfsdk:"entrypoint"`
+	Cmd               types.List   `tfsdk:"cmd"`
+	WorkingDir        types.String `tfsdk:"working_dir"`
+	User              types.String `tfsdk:"user"`
+	ExposedPorts      types.List   `tfsdk:"exposed_ports"`
+	Labels            types.Map    `tfsdk:"labels"`
+	Raw               types.String `tfsdk:"raw"`
+}
+
+func NewImageConfigDataSource() datasource.DataSource {
+	return &ImageConfigDataSource{}
+}
+
+func (d *ImageConfigDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_image_config"
+}
+
+func (d *ImageConfigDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Read the config of a container image, such as its environment, entrypoint and labels.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Equivalent to the requested reference.",
+				Computed:            true,
+			},
+			"reference": schema.StringAttribute{
+				MarkdownDescription: "A tag or digest identifying the image to inspect (for example `registry/repository:tag`).",
+				Required:            true,
+			},
+			"platform": schema.StringAttribute{
+				MarkdownDescription: "If reference is a multi-architecture image, the platform to read the config of in the form os/arch[/variant][:osversion] (e.g. linux/arm64). (default linux/amd64)",
+				Optional:            true,
+			},
+			"resolved_reference": schema.StringAttribute{
+				MarkdownDescription: "The location the config was actually read from. Differs from `reference` when the image was read through a provider `mirror`.",
+				Computed:            true,
+			},
+			"digest": schema.StringAttribute{
+				MarkdownDescription: "Content digest of the image manifest. For a multi-architecture image, this is the digest of the selected platform's manifest.",
+				Computed:            true,
+			},
+			"config_digest": schema.StringAttribute{
+				MarkdownDescription: "Content digest of the config blob, also known as the image ID.",
+				Computed:            true,
+			},
+			"os": schema.StringAttribute{
+				MarkdownDescription: "Operating system the image runs on.",
+				Computed:            true,
+			},
+			"architecture": schema.StringAttribute{
+				MarkdownDescription: "CPU architecture the image runs on.",
+				Computed:            true,
+			},
+			"variant": schema.StringAttribute{
+				MarkdownDescription: "CPU variant the image runs on.",
+				Computed:            true,
+			},
+			"created": schema.StringAttribute{
+				MarkdownDescription: "Time the image was created, in RFC 3339 format. Null when the config does not record it.",
+				Computed:            true,
+			},
+			"env": schema.ListAttribute{
+				MarkdownDescription: "Environment variables in the form `NAME=value`.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"entrypoint": schema.ListAttribute{
+				MarkdownDescription: "Entrypoint of the image. Null when unset.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"cmd": schema.ListAttribute{
+				MarkdownDescription: "Default arguments of the image. Null when unset.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"working_dir": schema.StringAttribute{
+				MarkdownDescription: "Working directory of the image.",
+				Computed:            true,
+			},
+			"user": schema.StringAttribute{
+				MarkdownDescription: "User the image runs as.",
+				Computed:            true,
+			},
+			"exposed_ports": schema.ListAttribute{
+				MarkdownDescription: "Exposed ports in the form `port/protocol` (e.g. `8080/tcp`), sorted.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"labels": schema.MapAttribute{
+				MarkdownDescription: "Labels of the image.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"raw": schema.StringAttribute{
+				MarkdownDescription: "The config blob as JSON, for fields not exposed as attributes. Use `jsondecode` to read it.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ImageConfigDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*registryClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *registryClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ImageConfigDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ImageConfigDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ref := data.Reference.ValueString()
+	options, err := setPlatform(d.client.options, data.Platform)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error parsing platform",
+			fmt.Sprintf("Unable to parse platform '%s': %s", data.Platform.ValueString(), err),
+		)
+		return
+	}
+	options = append(options, crane.WithContext(ctx))
+
+	resolved, err := d.client.resolveReference(ctx, ref, options)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading config for %q", ref), err.Error())
+		return
+	}
+
+	o := crane.GetOptions(options...)
+	parsed, err := name.ParseReference(resolved, o.Name...)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading config for %q", ref), err.Error())
+		return
+	}
+	img, err := remote.Image(parsed, o.Remote...)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading config for %q", ref), err.Error())
+		return
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading config for %q", ref), err.Error())
+		return
+	}
+	configDigest, err := img.ConfigName()
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading config for %q", ref), err.Error())
+		return
+	}
+	raw, err := img.RawConfigFile()
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading config for %q", ref), err.Error())
+		return
+	}
+	config, err := img.ConfigFile()
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("parsing config for %q", ref), err.Error())
+		return
+	}
+
+	data.ID = types.StringValue(ref)
+	data.ResolvedReference = types.StringValue(resolved)
+	data.Digest = types.StringValue(digest.String())
+	data.ConfigDigest = types.StringValue(configDigest.String())
+	data.Raw = types.StringValue(string(raw))
+	resp.Diagnostics.Append(data.setConfig(ctx, config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// setConfig records the fields of an image config in data.
+func (data *ImageConfigDataSourceModel) setConfig(ctx context.Context, config *v1.ConfigFile) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	data.OS = types.StringValue(config.OS)
+	data.Architecture = types.StringValue(config.Architecture)
+	data.Variant = types.StringValue(config.Variant)
+	data.Created = types.StringNull()
+	if !config.Created.IsZero() {
+		data.Created = types.StringValue(config.Created.UTC().Format(time.RFC3339))
+	}
+	data.WorkingDir = types.StringValue(config.Config.WorkingDir)
+	data.User = types.StringValue(config.Config.User)
+
+	ports := make([]string, 0, len(config.Config.ExposedPorts))
+	for port := range config.Config.ExposedPorts {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+
+	var d diag.Diagnostics
+	data.Env, d = types.ListValueFrom(ctx, types.StringType, config.Config.Env)
+	diags.Append(d...)
+	data.Entrypoint, d = types.ListValueFrom(ctx, types.StringType, config.Config.Entrypoint)
+	diags.Append(d...)
+	data.Cmd, d = types.ListValueFrom(ctx, types.StringType, config.Config.Cmd)
+	diags.Append(d...)
+	data.ExposedPorts, d = types.ListValueFrom(ctx, types.StringType, ports)
+	diags.Append(d...)
+	data.Labels, d = types.MapValueFrom(ctx, types.StringType, config.Config.Labels)
+	diags.Append(d...)
+	return diags
+}
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	testutils "github.com/adam-tylr/terraform-provider-crane/testing"
+	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccImageConfigDataSource(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	img, err := mutate.Config(empty.Image, v1.Config{
+		Env:          []string{"PATH=/usr/bin", "PORT=8080"},
+		Entrypoint:   []string{"/app"},
+		Cmd:          []string{"serve"},
+		WorkingDir:   "/srv",
+		User:         "1000:1000",
+		ExposedPorts: map[string]struct{}{"9090/tcp": {}, "8080/tcp": {}},
+		Labels:       map[string]string{"org.opencontainers.image.source": "https://example.com/app"},
+	})
+	if err != nil {
+		t.Fatalf("failed to build image: %v", err)
+	}
+	imageRef := fmt.Sprintf("%s:latest", repo)
+	if err := crane.Push(img, imageRef); err != nil {
+		t.Fatalf("failed to push image: %v", err)
+	}
+	configDigest, err := img.ConfigName()
+	if err != nil {
+		t.Fatalf("failed to read config digest: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccImageConfigDataSourceConfig, imageRef),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("config_digest"),
+						knownvalue.StringExact(configDigest.String()),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("env"),
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("PATH=/usr/bin"),
+							knownvalue.StringExact("PORT=8080"),
+						}),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("entrypoint"),
+						knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact("/app")}),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("working_dir"),
+						knownvalue.StringExact("/srv"),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("user"),
+						knownvalue.StringExact("1000:1000"),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("exposed_ports"),
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("8080/tcp"),
+							knownvalue.StringExact("9090/tcp"),
+						}),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("labels"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"org.opencontainers.image.source": knownvalue.StringExact("https://example.com/app"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageConfigDataSourcePlatform(t *testing.T) {
+	imageRef := testutils.CreateSourceRef("docker/library/alpine:latest")
+	digest, err := crane.Digest(imageRef, crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"}))
+	if err != nil {
+		t.Fatalf("failed to read digest for %s: %v", imageRef, err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccImageConfigDataSourceConfigWithPlatform, imageRef, "linux/arm64"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(digest),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("architecture"),
+						knownvalue.StringExact("arm64"),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_config.test",
+						tfjsonpath.New("entrypoint"),
+						knownvalue.Null(),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestImageConfigDataSourceModelSetConfig(t *testing.T) {
+	var data ImageConfigDataSourceModel
+	diags := data.setConfig(t.Context(), &v1.ConfigFile{
+		OS:           "linux",
+		Architecture: "arm64",
+		Variant:      "v8",
+		Created:      v1.Time{Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))},
+		Config: v1.Config{
+			ExposedPorts: map[string]struct{}{"53/udp": {}, "443/tcp": {}},
+		},
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if got := data.Created.ValueString(); got != "2024-05-01T10:00:00Z" {
+		t.Errorf("expected created in UTC, got %s", got)
+	}
+	var ports []string
+	if diags := data.ExposedPorts.ElementsAs(t.Context(), &ports, false); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if len(ports) != 2 || ports[0] != "443/tcp" || ports[1] != "53/udp" {
+		t.Errorf("expected sorted exposed ports, got %v", ports)
+	}
+	if !data.Entrypoint.IsNull() || !data.Cmd.IsNull() {
+		t.Errorf("expected unset entrypoint and cmd to be null, got %v and %v", data.Entrypoint, data.Cmd)
+	}
+
+	data.setConfig(t.Context(), &v1.ConfigFile{})
+	if !data.Created.IsNull() {
+		t.Errorf("expected unset created to be null, got %s", data.Created)
+	}
+}
+
+const testAccImageConfigDataSourceConfig = `
+data "crane_image_config" "test" {
+  reference = "%s"
+}
+`
+
+const testAccImageConfigDataSourceConfigWithPlatform = `
+data "crane_image_config" "test" {
+  reference = "%s"
+  platform  = "%s"
+}
+`
@@ -0,0 +1,514 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	indexFormatOCI    = "oci"
+	indexFormatDocker = "docker"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ImageIndexResource{}
+var _ resource.ResourceWithConfigure = &ImageIndexResource{}
+var _ resource.ResourceWithModifyPlan = &ImageIndexResource{}
+
+func NewImageIndexResource() resource.Resource {
+	return &ImageIndexResource{}
+}
+
+// ImageIndexResource defines the resource implementation.
+type ImageIndexResource struct {
+	client *registryClient
+}
+
+// ImageIndexResourceModel describes the resource data model.
+type ImageIndexResourceModel struct {
+	Id              types.String `tfsdk:"id"`
+	Destination     types.String `tfsdk:"destination"`
+	Manifests       types.List   `tfsdk:"manifests"`
+	Format          types.String `tfsdk:"format"`
+	Annotations     types.Map    `tfsdk:"annotations"`
+	ManifestDigests types.List   `tfsdk:"manifest_digests"`
+	Digest          types.String `tfsdk:"digest"`
+	PinnedReference types.String `tfsdk:"pinned_reference"`
+}
+
+// indexManifestModel describes an image to add to the index.
+type indexManifestModel struct {
+	Reference   types.String `tfsdk:"reference"`
+	Platform    types.String `tfsdk:"platform"`
+	Annotations types.Map    `tfsdk:"annotations"`
+}
+
+func (r *ImageIndexResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_image_index"
+}
+
+func (r *ImageIndexResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Combine separately built images into a multi-architecture image index and push it, equivalent to ` + "`docker manifest create`" + ` followed by ` + "`docker manifest push`" + `.
+
+Only the manifests of the images are read, and their layers are copied to the destination repository when they are not already there. The index is rebuilt when one of ` + "`manifests`" + ` resolves to a different image, or when the index in the registry no longer matches the configuration. Destroying the resource does not delete the index from the repository.`,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Equivalent to `destination`.",
+			},
+			"destination": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The destination to push the index to (`registry/repo` or `registry/repo:tag`).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"manifests": schema.ListNestedAttribute{
+				Required:            true,
+				MarkdownDescription: "The images to include in the index, in order.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"reference": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "A tag or digest identifying the image (for example `registry/repository:sha-amd64`). If it is an index, it must contain a single image matching `platform`, ignoring attestations.",
+						},
+						"platform": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "The platform of the image in the form os/arch[/variant][:osversion] (e.g. linux/arm64). (default the platform in the image config)",
+						},
+						"annotations": schema.MapAttribute{
+							Optional:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "Annotations to set on the descriptor of the image in the index. Only supported by the `oci` format.",
+						},
+					},
+				},
+			},
+			"format": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The format of the index, either `oci` for an OCI image index or `docker` for a Docker manifest list. (default `oci`)",
+				Validators: []validator.String{
+					stringvalidator.OneOf(indexFormatOCI, indexFormatDocker),
+				},
+			},
+			"annotations": schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Annotations to set on the index. Only supported by the `oci` format.",
+			},
+			"manifest_digests": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The digests of the images in the index, as last read from the registry.",
+			},
+			"digest": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The digest of the pushed index.",
+			},
+			"pinned_reference": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The immutable reference of the pushed index pinned by digest (`registry/repo@sha256:...`).",
+			},
+		},
+	}
+}
+
+func (r *ImageIndexResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*registryClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *registryClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan composes the index from the current images so that an update is planned when
+// it differs from the index last read from the registry.
+func (r *ImageIndexResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan, state ImageIndexResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	idx, unreachable, diags := r.compose(ctx, &plan)
+	if diags.HasError() {
+		if !unreachable {
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		// The images may not be reachable until apply, so the index is rebuilt then.
+		for _, d := range diags.Errors() {
+			resp.Diagnostics.AddWarning(d.Summary(), d.Detail())
+		}
+		return
+	}
+	hash, err := idx.Digest()
+	if err != nil {
+		resp.Diagnostics.AddError("Error composing image index", fmt.Sprintf("Unable to compute the digest of the image index: %s", err))
+		return
+	}
+
+	if hash.String() == state.Digest.ValueString() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("digest"), state.Digest)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("pinned_reference"), state.PinnedReference)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("manifest_digests"), state.ManifestDigests)...)
+		return
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Image index '%s' has digest '%s', want '%s'", state.Id.ValueString(), state.Digest.ValueString(), hash))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("digest"), types.StringUnknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("pinned_reference"), types.StringUnknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("manifest_digests"), types.ListUnknown(types.StringType))...)
+}
+
+func (r *ImageIndexResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ImageIndexResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.push(ctx, &data, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ImageIndexResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ImageIndexResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	destination := data.Id.ValueString()
+	o := crane.GetOptions(r.client.options...)
+	ref, err := name.ParseReference(destination, o.Name...)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error parsing image reference from state",
+			fmt.Sprintf("Unable to parse image reference '%s': %s", destination, err),
+		)
+		return
+	}
+
+	desc, err := remote.Get(ref, o.Remote...)
+	if err != nil {
+		var remoteErr *transport.Error
+		if errors.As(err, &remoteErr) && remoteErr.StatusCode == http.StatusNotFound {
+			resp.Diagnostics.AddWarning(
+				"Image Not Found",
+				fmt.Sprintf("Image index '%s' not found in the registry. It may have been deleted or never pushed.", destination),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error fetching image from registry",
+			fmt.Sprintf("Unable to fetch image index '%s' from the registry: %s", destination, err),
+		)
+		return
+	}
+
+	// The digests of the images actually in the registry are recorded, so that an index that
+	// was overwritten outside of Terraform is planned to be pushed again.
+	digests := []string{}
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error fetching image from registry",
+				fmt.Sprintf("Unable to read image index '%s': %s", destination, err),
+			)
+			return
+		}
+		manifest, err := idx.IndexManifest()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error fetching image from registry",
+				fmt.Sprintf("Unable to read image index '%s': %s", destination, err),
+			)
+			return
+		}
+		for _, child := range manifest.Manifests {
+			digests = append(digests, child.Digest.String())
+		}
+	}
+	if desc.Digest.String() != data.Digest.ValueString() {
+		tflog.Debug(ctx, fmt.Sprintf("Image index '%s' now has digest '%s'", destination, desc.Digest))
+	}
+
+	manifestDigests, diags := types.ListValueFrom(ctx, types.StringType, digests)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ManifestDigests = manifestDigests
+	data.Digest = types.StringValue(desc.Digest.String())
+	data.PinnedReference = pinnedReference(destination, desc.Digest.String(), o)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ImageIndexResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data ImageIndexResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.push(ctx, &data, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ImageIndexResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// push composes the index described by data, pushes it to its destination and records the
+// computed attributes in data. When failOnConflict is set, a destination already holding a
+// different image is reported as a conflict instead of being overwritten.
+func (r *ImageIndexResource) push(ctx context.Context, data *ImageIndexResourceModel, failOnConflict bool) diag.Diagnostics {
+	idx, _, diags := r.compose(ctx, data)
+	if diags.HasError() {
+		return diags
+	}
+
+	hash, err := idx.Digest()
+	if err != nil {
+		diags.AddError("Error composing image index", fmt.Sprintf("Unable to compute the digest of the image index: %s", err))
+		return diags
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		diags.AddError("Error composing image index", fmt.Sprintf("Unable to compose the image index: %s", err))
+		return diags
+	}
+	digest := hash.String()
+	digests := make([]string, 0, len(manifest.Manifests))
+	for _, child := range manifest.Manifests {
+		digests = append(digests, child.Digest.String())
+	}
+
+	destination := data.Destination.ValueString()
+	opts := r.client.options
+	onConflict := onConflictOverwrite
+	if failOnConflict {
+		onConflict = onConflictFail
+	}
+	if _, d := pushDestination(ctx, idx, "image index", digest, destination, opts, onConflict); d != nil {
+		diags.Append(d)
+		return diags
+	}
+
+	manifestDigests, d := types.ListValueFrom(ctx, types.StringType, digests)
+	diags.Append(d...)
+	data.Id = types.StringValue(destination)
+	data.ManifestDigests = manifestDigests
+	data.Digest = types.StringValue(digest)
+	data.PinnedReference = pinnedReference(destination, digest, crane.GetOptions(opts...))
+	return diags
+}
+
+// compose builds the index described by data from the images in the registry. It also
+// reports whether it failed because an image could not be reached in the registry.
+func (r *ImageIndexResource) compose(ctx context.Context, data *ImageIndexResourceModel) (v1.ImageIndex, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var manifests []indexManifestModel
+	diags.Append(data.Manifests.ElementsAs(ctx, &manifests, false)...)
+	var annotations map[string]string
+	if !data.Annotations.IsNull() {
+		diags.Append(data.Annotations.ElementsAs(ctx, &annotations, false)...)
+	}
+	if diags.HasError() {
+		return nil, false, diags
+	}
+
+	docker := data.Format.ValueString() == indexFormatDocker
+	if docker && len(annotations) > 0 {
+		diags.AddAttributeError(path.Root("annotations"), "Invalid annotations", "Annotations are not supported by the docker format.")
+		return nil, false, diags
+	}
+
+	addenda := make([]mutate.IndexAddendum, 0, len(manifests))
+	for i, manifest := range manifests {
+		attrPath := path.Root("manifests").AtListIndex(i)
+		var childAnnotations map[string]string
+		if !manifest.Annotations.IsNull() {
+			diags.Append(manifest.Annotations.ElementsAs(ctx, &childAnnotations, false)...)
+			if diags.HasError() {
+				return nil, false, diags
+			}
+		}
+		if docker && len(childAnnotations) > 0 {
+			diags.AddAttributeError(attrPath.AtName("annotations"), "Invalid annotations", "Annotations are not supported by the docker format.")
+			return nil, false, diags
+		}
+
+		var platform *v1.Platform
+		if !manifest.Platform.IsNull() {
+			p, err := v1.ParsePlatform(manifest.Platform.ValueString())
+			if err != nil {
+				diags.AddAttributeError(
+					attrPath.AtName("platform"),
+					"Error parsing platform",
+					fmt.Sprintf("Unable to parse platform '%s': %s", manifest.Platform.ValueString(), err),
+				)
+				return nil, false, diags
+			}
+			platform = p
+		}
+
+		reference := manifest.Reference.ValueString()
+		img, err := r.indexImage(ctx, reference, platform)
+		if err != nil {
+			diags.AddAttributeError(
+				attrPath.AtName("reference"),
+				"Error reading image",
+				fmt.Sprintf("Unable to read image '%s': %s", reference, err),
+			)
+			return nil, registryUnavailable(err), diags
+		}
+		if platform == nil {
+			config, err := img.ConfigFile()
+			if err != nil {
+				diags.AddAttributeError(
+					attrPath.AtName("reference"),
+					"Error reading image",
+					fmt.Sprintf("Unable to read the config of image '%s': %s", reference, err),
+				)
+				return nil, false, diags
+			}
+			platform = config.Platform()
+		}
+		addenda = append(addenda, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: platform, Annotations: childAnnotations},
+		})
+	}
+
+	mediaType := ggcrtypes.OCIImageIndex
+	if docker {
+		mediaType = ggcrtypes.DockerManifestList
+	}
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, mediaType), addenda...)
+	if len(annotations) > 0 {
+		annotated, ok := mutate.Annotations(idx, annotations).(v1.ImageIndex)
+		if !ok {
+			diags.AddError("Error composing image index", "Unable to annotate the image index.")
+			return nil, false, diags
+		}
+		idx = annotated
+	}
+	return idx, false, diags
+}
+
+// indexImage reads the image at reference. An index is accepted as long as it holds a
+// single image matching platform, which is how single platform images are often pushed
+// along with their attestations.
+func (r *ImageIndexResource) indexImage(ctx context.Context, reference string, platform *v1.Platform) (v1.Image, error) {
+	opts := append([]crane.Option{}, r.client.options...)
+	opts = append(opts, crane.WithContext(ctx))
+	resolved, err := r.client.resolveReference(ctx, reference, opts)
+	if err != nil {
+		return nil, err
+	}
+	o := crane.GetOptions(opts...)
+	ref, err := name.ParseReference(resolved, o.Name...)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := remote.Get(ref, o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsIndex() {
+		return desc.Image()
+	}
+
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var matches []v1.Descriptor
+	for _, child := range imagePlatforms(manifest) {
+		if child.MediaType.IsImage() && (platform == nil || child.Platform.Satisfies(*platform)) {
+			matches = append(matches, child)
+		}
+	}
+	if len(matches) != 1 {
+		platforms := make([]string, 0, len(matches))
+		for _, child := range matches {
+			platforms = append(platforms, child.Platform.String())
+		}
+		return nil, fmt.Errorf("the image is an index with %d matching images [%s], set platform to select one", len(matches), strings.Join(platforms, ", "))
+	}
+	return idx.Image(matches[0].Digest)
+}
+
+// registryUnavailable reports whether err is a failure to reach an image in its registry,
+// which may be resolved by the time the plan is applied.
+func registryUnavailable(err error) bool {
+	var remoteErr *transport.Error
+	var netErr net.Error
+	return errors.As(err, &remoteErr) || errors.As(err, &netErr)
+}
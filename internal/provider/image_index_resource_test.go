@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	testutils "github.com/adam-tylr/terraform-provider-crane/testing"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+// pushPlatformImage pushes a random image for platform to ref and returns its digest.
+func pushPlatformImage(t *testing.T, ref string, platform v1.Platform) string {
+	t.Helper()
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	cfg = cfg.DeepCopy()
+	cfg.OS, cfg.Architecture, cfg.Variant = platform.OS, platform.Architecture, platform.Variant
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+	if err := crane.Push(img, ref); err != nil {
+		t.Fatalf("failed to push image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	return digest.String()
+}
+
+func TestImageIndexImage(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// A single platform image pushed with an attestation, as done by buildx.
+	amd64 := v1.Platform{OS: "linux", Architecture: "amd64"}
+	digest := pushPlatformImage(t, host+"/app:sha-amd64", amd64)
+	img, err := crane.Pull(host + "/app:sha-amd64")
+	if err != nil {
+		t.Fatalf("failed to read image: %v", err)
+	}
+	attestation, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex),
+		mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &amd64}},
+		mutate.IndexAddendum{Add: attestation, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"}}},
+	)
+	ref, err := name.ParseReference(host + "/app:sha-amd64-index")
+	if err != nil {
+		t.Fatalf("failed to parse reference: %v", err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatalf("failed to push index: %v", err)
+	}
+
+	r := &ImageIndexResource{client: &registryClient{}}
+	for _, platform := range []*v1.Platform{nil, &amd64} {
+		got, err := r.indexImage(t.Context(), ref.String(), platform)
+		if err != nil {
+			t.Fatalf("indexImage(%v) error = %v", platform, err)
+		}
+		gotDigest, err := got.Digest()
+		if err != nil {
+			t.Fatalf("failed to read digest: %v", err)
+		}
+		if gotDigest.String() != digest {
+			t.Errorf("indexImage(%v) = %s, want %s", platform, gotDigest, digest)
+		}
+	}
+
+	_, err = r.indexImage(t.Context(), ref.String(), &v1.Platform{OS: "linux", Architecture: "arm64"})
+	if err == nil {
+		t.Error("indexImage() of a platform missing from the index succeeded, want an error")
+	} else if registryUnavailable(err) {
+		t.Errorf("registryUnavailable(%v) = true, want false", err)
+	}
+	if _, err := r.indexImage(t.Context(), ref.Context().Tag("missing").String(), nil); !registryUnavailable(err) {
+		t.Errorf("registryUnavailable(%v) = false, want true", err)
+	}
+}
+
+func TestAccImageIndexResource(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	amd64 := pushPlatformImage(t, repo+":sha-amd64", v1.Platform{OS: "linux", Architecture: "amd64"})
+	arm64 := pushPlatformImage(t, repo+":sha-arm64", v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"})
+	destination := repo + ":sha"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccImageIndex(repo, destination),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image_index.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(destination),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image_index.test",
+						tfjsonpath.New("manifest_digests"),
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact(amd64),
+							knownvalue.StringExact(arm64),
+						}),
+					),
+				},
+			},
+			// Rebuilding one of the images updates the index
+			{
+				PreConfig: func() {
+					pushPlatformImage(t, repo+":sha-arm64", v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"})
+				},
+				Config: testAccImageIndex(repo, destination),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image_index.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+			},
+			// Overwriting the index outside of Terraform pushes it again
+			{
+				PreConfig: func() {
+					if err := crane.Copy(repo+":sha-amd64", destination); err != nil {
+						t.Fatalf("failed to overwrite index: %v", err)
+					}
+				},
+				Config: testAccImageIndex(repo, destination),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image_index.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+			},
+			// An image that cannot be added to the index fails the plan
+			{
+				PreConfig: func() {
+					if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:latest"), repo+":multi"); err != nil {
+						t.Fatalf("failed to copy multi-platform image: %v", err)
+					}
+				},
+				Config:      testAccImageIndexManifests(destination, repo+":sha-amd64", repo+":multi"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("matching images"),
+			},
+			// An image missing from the registry may be pushed before the apply, so the plan
+			// only warns
+			{
+				Config:             testAccImageIndexManifests(destination, repo+":sha-amd64", repo+":missing"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:   testAccImageIndex(repo, destination),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccImageIndex(repo string, destination string) string {
+	return fmt.Sprintf(`
+resource "crane_image_index" "test" {
+  destination = %[2]q
+
+  manifests = [
+    {
+      reference = "%[1]s:sha-amd64"
+    },
+    {
+      reference = "%[1]s:sha-arm64"
+      annotations = {
+        "org.opencontainers.image.revision" = "abc123"
+      }
+    },
+  ]
+
+  annotations = {
+    "org.opencontainers.image.source" = "https://example.com/app"
+  }
+}
+`, repo, destination)
+}
+
+func testAccImageIndexManifests(destination string, references ...string) string {
+	var manifests strings.Builder
+	for _, reference := range references {
+		fmt.Fprintf(&manifests, "    {\n      reference = %q\n    },\n", reference)
+	}
+	return fmt.Sprintf(`
+resource "crane_image_index" "test" {
+  destination = %q
+
+  manifests = [
+%s  ]
+}
+`, destination, manifests.String())
+}
@@ -0,0 +1,434 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ImageManifestDataSource{}
+
+// ImageManifestDataSource reads the manifest of an image or index.
+type ImageManifestDataSource struct {
+	client *registryClient
+}
+
+// ImageManifestDataSourceModel describes the data source model.
+type ImageManifestDataSourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Reference         types.String `tfsdk:"reference"`
+	ResolvedReference types.String `tfsdk:"resolved_reference"`
+	Digest            types.String `tfsdk:"digest"`
+	Size              types.Int64  `tfsdk:"size"`
+	MediaType         types.String `tfsdk:"media_type"`
+	SchemaVersion     types.Int64  `tfsdk:"schema_version"`
+	Annotations       types.Map    `tfsdk:"annotations"`
+	Config            types.Object `tfsdk:"config"`
+	Layers            types.List   `tfsdk:"layers"`
+	Manifests         types.List   `tfsdk:"manifests"`
+}
+
+// descriptorModel describes a blob referenced by a manifest.
+type descriptorModel struct {
+	Digest      types.String `tfsdk:"digest"`
+	Size        types.Int64  `tfsdk:"size"`
+	MediaType   types.String `tfsdk:"media_type"`
+	Annotations types.Map    `tfsdk:"annotations"`
+	URLs        types.List   `tfsdk:"urls"`
+}
+
+var descriptorAttrTypes = map[string]attr.Type{
+	"digest":      types.StringType,
+	"size":        types.Int64Type,
+	"media_type":  types.StringType,
+	"annotations": types.MapType{ElemType: types.StringType},
+	"urls":        types.ListType{ElemType: types.StringType},
+}
+
+// childManifestModel describes a manifest referenced by an index.
+type childManifestModel struct {
+	Digest      types.String `tfsdk:"digest"`
+	Size        types.Int64  `tfsdk:"size"`
+	MediaType   types.String `tfsdk:"media_type"`
+	Annotations types.Map    `tfsdk:"annotations"`
+	Platform    types.Object `tfsdk:"platform"`
+}
+
+var childManifestAttrTypes = map[string]attr.Type{
+	"digest":      types.StringType,
+	"size":        types.Int64Type,
+	"media_type":  types.StringType,
+	"annotations": types.MapType{ElemType: types.StringType},
+	"platform":    types.ObjectType{AttrTypes: platformAttrTypes},
+}
+
+// platformModel describes the platform an image runs on.
+type platformModel struct {
+	OS           types.String `tfsdk:"os"`
+	Architecture types.String `tfsdk:"architecture"`
+	Variant      types.String `tfsdk:"variant"`
+	OSVersion    types.String `tfsdk:"os_version"`
+	OSFeatures   types.List   `tfsdk:"os_features"`
+	Features     types.List   `tfsdk:"features"`
+}
+
+var platformAttrTypes = map[string]attr.Type{
+	"os":           types.StringType,
+	"architecture": types.StringType,
+	"variant":      types.StringType,
+	"os_version":   types.StringType,
+	"os_features":  types.ListType{ElemType: types.StringType},
+	"features":     types.ListType{ElemType: types.StringType},
+}
+
+func NewImageManifestDataSource() datasource.DataSource {
+	return &ImageManifestDataSource{}
+}
+
+func (d *ImageManifestDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_image_manifest"
+}
+
+func (d *ImageManifestDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Read the manifest of a container image or image index.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Equivalent to the requested reference.",
+				Computed:            true,
+			},
+			"reference": schema.StringAttribute{
+				MarkdownDescription: "A tag or digest identifying the image to inspect (for example `registry/repository:tag`).",
+				Required:            true,
+			},
+			"resolved_reference": schema.StringAttribute{
+				MarkdownDescription: "The location the manifest was actually read from. Differs from `reference` when the image was read through a provider `mirror`.",
+				Computed:            true,
+			},
+			"digest": schema.StringAttribute{
+				MarkdownDescription: "Content digest of the manifest, such as `sha256:...`.",
+				Computed:            true,
+			},
+			"size": schema.Int64Attribute{
+				MarkdownDescription: "Size of the manifest in bytes.",
+				Computed:            true,
+			},
+			"media_type": schema.StringAttribute{
+				MarkdownDescription: "Media type of the manifest, such as `application/vnd.oci.image.manifest.v1+json` or `application/vnd.docker.distribution.manifest.list.v2+json`.",
+				Computed:            true,
+			},
+			"schema_version": schema.Int64Attribute{
+				MarkdownDescription: "Schema version of the manifest.",
+				Computed:            true,
+			},
+			"annotations": schema.MapAttribute{
+				MarkdownDescription: "Annotations of the manifest.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"config": schema.SingleNestedAttribute{
+				MarkdownDescription: "Descriptor of the image config. Null for indexes.",
+				Computed:            true,
+				Attributes:          descriptorSchemaAttributes(),
+			},
+			"layers": schema.ListNestedAttribute{
+				MarkdownDescription: "Descriptors of the image layers, from the base layer up. Null for indexes.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: descriptorSchemaAttributes(),
+				},
+			},
+			"manifests": schema.ListNestedAttribute{
+				MarkdownDescription: "Manifests referenced by an index, in index order. Null for images.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"digest": schema.StringAttribute{
+							MarkdownDescription: "Content digest of the manifest.",
+							Computed:            true,
+						},
+						"size": schema.Int64Attribute{
+							MarkdownDescription: "Size of the manifest in bytes.",
+							Computed:            true,
+						},
+						"media_type": schema.StringAttribute{
+							MarkdownDescription: "Media type of the manifest.",
+							Computed:            true,
+						},
+						"annotations": schema.MapAttribute{
+							MarkdownDescription: "Annotations of the manifest descriptor.",
+							Computed:            true,
+							ElementType:         types.StringType,
+						},
+						"platform": schema.SingleNestedAttribute{
+							MarkdownDescription: "Platform the manifest runs on. Null when the index does not specify one.",
+							Computed:            true,
+							Attributes:          platformSchemaAttributes(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// descriptorSchemaAttributes returns the schema of a computed blob descriptor.
+func descriptorSchemaAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"digest": schema.StringAttribute{
+			MarkdownDescription: "Content digest of the blob.",
+			Computed:            true,
+		},
+		"size": schema.Int64Attribute{
+			MarkdownDescription: "Size of the blob in bytes.",
+			Computed:            true,
+		},
+		"media_type": schema.StringAttribute{
+			MarkdownDescription: "Media type of the blob. Foreign (non-distributable) layers have a `foreign` or `nondistributable` media type.",
+			Computed:            true,
+		},
+		"annotations": schema.MapAttribute{
+			MarkdownDescription: "Annotations of the descriptor.",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+		"urls": schema.ListAttribute{
+			MarkdownDescription: "URLs the blob may be downloaded from, set for foreign layers.",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+	}
+}
+
+// platformSchemaAttributes returns the schema of a computed platform.
+func platformSchemaAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"os": schema.StringAttribute{
+			MarkdownDescription: "Operating system, such as `linux`.",
+			Computed:            true,
+		},
+		"architecture": schema.StringAttribute{
+			MarkdownDescription: "CPU architecture, such as `amd64` or `arm64`.",
+			Computed:            true,
+		},
+		"variant": schema.StringAttribute{
+			MarkdownDescription: "CPU variant, such as `v8`.",
+			Computed:            true,
+		},
+		"os_version": schema.StringAttribute{
+			MarkdownDescription: "Operating system version, set for Windows images.",
+			Computed:            true,
+		},
+		"os_features": schema.ListAttribute{
+			MarkdownDescription: "Required operating system features.",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+		"features": schema.ListAttribute{
+			MarkdownDescription: "Required CPU features.",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+	}
+}
+
+func (d *ImageManifestDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*registryClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *registryClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ImageManifestDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ImageManifestDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ref := data.Reference.ValueString()
+	options := append([]crane.Option{}, d.client.options...)
+	options = append(options, crane.WithContext(ctx))
+
+	resolved, err := d.client.resolveReference(ctx, ref, options)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading manifest for %q", ref), err.Error())
+		return
+	}
+
+	o := crane.GetOptions(options...)
+	parsed, err := name.ParseReference(resolved, o.Name...)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading manifest for %q", ref), err.Error())
+		return
+	}
+	desc, err := remote.Get(parsed, o.Remote...)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading manifest for %q", ref), err.Error())
+		return
+	}
+
+	data.ID = types.StringValue(ref)
+	data.ResolvedReference = types.StringValue(resolved)
+	data.Digest = types.StringValue(desc.Digest.String())
+	data.Size = types.Int64Value(desc.Size)
+	data.MediaType = types.StringValue(string(desc.MediaType))
+	data.Config = types.ObjectNull(descriptorAttrTypes)
+	data.Layers = types.ListNull(types.ObjectType{AttrTypes: descriptorAttrTypes})
+	data.Manifests = types.ListNull(types.ObjectType{AttrTypes: childManifestAttrTypes})
+
+	switch {
+	case desc.MediaType.IsIndex():
+		index, err := desc.ImageIndex()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("reading manifest for %q", ref), err.Error())
+			return
+		}
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("parsing index manifest for %q", ref), err.Error())
+			return
+		}
+		resp.Diagnostics.Append(data.setIndexManifest(ctx, manifest)...)
+	case desc.MediaType.IsImage():
+		img, err := desc.Image()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("reading manifest for %q", ref), err.Error())
+			return
+		}
+		manifest, err := img.Manifest()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("parsing manifest for %q", ref), err.Error())
+			return
+		}
+		resp.Diagnostics.Append(data.setImageManifest(ctx, manifest)...)
+	default:
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("reading manifest for %q", ref),
+			fmt.Sprintf("unsupported manifest media type %q", desc.MediaType),
+		)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// setImageManifest records the fields of an image manifest in data.
+func (data *ImageManifestDataSourceModel) setImageManifest(ctx context.Context, manifest *v1.Manifest) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	data.SchemaVersion = types.Int64Value(manifest.SchemaVersion)
+	annotations, d := types.MapValueFrom(ctx, types.StringType, manifest.Annotations)
+	diags.Append(d...)
+	data.Annotations = annotations
+
+	config, d := descriptorValue(ctx, manifest.Config)
+	diags.Append(d...)
+	data.Config = config
+
+	layers := make([]attr.Value, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		value, d := descriptorValue(ctx, layer)
+		diags.Append(d...)
+		layers = append(layers, value)
+	}
+	data.Layers, d = types.ListValue(types.ObjectType{AttrTypes: descriptorAttrTypes}, layers)
+	diags.Append(d...)
+	return diags
+}
+
+// setIndexManifest records the fields of an index manifest in data.
+func (data *ImageManifestDataSourceModel) setIndexManifest(ctx context.Context, manifest *v1.IndexManifest) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	data.SchemaVersion = types.Int64Value(manifest.SchemaVersion)
+	annotations, d := types.MapValueFrom(ctx, types.StringType, manifest.Annotations)
+	diags.Append(d...)
+	data.Annotations = annotations
+
+	manifests := make([]attr.Value, 0, len(manifest.Manifests))
+	for _, child := range manifest.Manifests {
+		childAnnotations, d := types.MapValueFrom(ctx, types.StringType, child.Annotations)
+		diags.Append(d...)
+		platform, d := platformValue(ctx, child.Platform)
+		diags.Append(d...)
+		value, d := types.ObjectValueFrom(ctx, childManifestAttrTypes, childManifestModel{
+			Digest:      types.StringValue(child.Digest.String()),
+			Size:        types.Int64Value(child.Size),
+			MediaType:   types.StringValue(string(child.MediaType)),
+			Annotations: childAnnotations,
+			Platform:    platform,
+		})
+		diags.Append(d...)
+		manifests = append(manifests, value)
+	}
+	data.Manifests, d = types.ListValue(types.ObjectType{AttrTypes: childManifestAttrTypes}, manifests)
+	diags.Append(d...)
+	return diags
+}
+
+// descriptorValue converts a blob descriptor to its Terraform value.
+func descriptorValue(ctx context.Context, desc v1.Descriptor) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	annotations, d := types.MapValueFrom(ctx, types.StringType, desc.Annotations)
+	diags.Append(d...)
+	urls, d := types.ListValueFrom(ctx, types.StringType, desc.URLs)
+	diags.Append(d...)
+	value, d := types.ObjectValueFrom(ctx, descriptorAttrTypes, descriptorModel{
+		Digest:      types.StringValue(desc.Digest.String()),
+		Size:        types.Int64Value(desc.Size),
+		MediaType:   types.StringValue(string(desc.MediaType)),
+		Annotations: annotations,
+		URLs:        urls,
+	})
+	diags.Append(d...)
+	return value, diags
+}
+
+// platformValue converts a platform to its Terraform value. A nil platform is null.
+func platformValue(ctx context.Context, platform *v1.Platform) (types.Object, diag.Diagnostics) {
+	if platform == nil {
+		return types.ObjectNull(platformAttrTypes), nil
+	}
+
+	var diags diag.Diagnostics
+	osFeatures, d := types.ListValueFrom(ctx, types.StringType, platform.OSFeatures)
+	diags.Append(d...)
+	features, d := types.ListValueFrom(ctx, types.StringType, platform.Features)
+	diags.Append(d...)
+	value, d := types.ObjectValueFrom(ctx, platformAttrTypes, platformModel{
+		OS:           types.StringValue(platform.OS),
+		Architecture: types.StringValue(platform.Architecture),
+		Variant:      types.StringValue(platform.Variant),
+		OSVersion:    types.StringValue(platform.OSVersion),
+		OSFeatures:   osFeatures,
+		Features:     features,
+	})
+	diags.Append(d...)
+	return value, diags
+}
@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	testutils "github.com/adam-tylr/terraform-provider-crane/testing"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccImageManifestDataSourceIndex(t *testing.T) {
+	imageRef := testutils.CreateSourceRef("docker/library/alpine:latest")
+	expectedDigest, err := crane.Digest(imageRef)
+	if err != nil {
+		t.Fatalf("failed to read digest for %s: %v", imageRef, err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccImageManifestDataSourceConfig, imageRef),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(expectedDigest),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("media_type"),
+						knownvalue.StringExact(string(types.DockerManifestList)),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("schema_version"),
+						knownvalue.Int64Exact(2),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("layers"),
+						knownvalue.Null(),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("manifests"),
+						knownvalue.ListSizeExact(2),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("manifests").AtSliceIndex(1).AtMapKey("platform").AtMapKey("architecture"),
+						knownvalue.StringExact("arm64"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageManifestDataSourceImage(t *testing.T) {
+	imageRef := testutils.CreateSourceRef("docker/library/alpine:latest")
+	platform := crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "amd64"})
+	manifest, err := crane.Manifest(imageRef, platform)
+	if err != nil {
+		t.Fatalf("failed to read manifest for %s: %v", imageRef, err)
+	}
+	parsed, err := v1.ParseManifest(bytes.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("failed to parse manifest for %s: %v", imageRef, err)
+	}
+	digest, err := crane.Digest(imageRef, platform)
+	if err != nil {
+		t.Fatalf("failed to read digest for %s: %v", imageRef, err)
+	}
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", imageRef, err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccImageManifestDataSourceConfig, ref.Context().Digest(digest)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(digest),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("config").AtMapKey("digest"),
+						knownvalue.StringExact(parsed.Config.Digest.String()),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("layers"),
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.ObjectPartial(map[string]knownvalue.Check{
+								"digest": knownvalue.StringExact(parsed.Layers[0].Digest.String()),
+								"size":   knownvalue.Int64Exact(parsed.Layers[0].Size),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownValue(
+						"data.crane_image_manifest.test",
+						tfjsonpath.New("manifests"),
+						knownvalue.Null(),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestImageManifestDataSourceModelSetImageManifest(t *testing.T) {
+	manifest := &v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config:        v1.Descriptor{MediaType: types.OCIConfigJSON, Size: 10, Digest: v1.Hash{Algorithm: "sha256", Hex: "c0"}},
+		Layers: []v1.Descriptor{
+			{MediaType: types.OCILayer, Size: 20, Digest: v1.Hash{Algorithm: "sha256", Hex: "a1"}},
+			{
+				MediaType:   types.OCIRestrictedLayer,
+				Size:        30,
+				Digest:      v1.Hash{Algorithm: "sha256", Hex: "a2"},
+				URLs:        []string{"https://example.com/layer"},
+				Annotations: map[string]string{"owner": "vendor"},
+			},
+		},
+	}
+
+	var data ImageManifestDataSourceModel
+	if diags := data.setImageManifest(t.Context(), manifest); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var layers []descriptorModel
+	if diags := data.Layers.ElementsAs(t.Context(), &layers, false); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if len(layers) != 2 {
+		t.Fatalf("expected 2 layers, got %d", len(layers))
+	}
+	if got := layers[0].Digest.ValueString(); got != "sha256:a1" {
+		t.Errorf("expected base layer first, got %s", got)
+	}
+	if got := layers[1].MediaType.ValueString(); got != string(types.OCIRestrictedLayer) {
+		t.Errorf("expected foreign layer media type, got %s", got)
+	}
+	var urls []string
+	if diags := layers[1].URLs.ElementsAs(t.Context(), &urls, false); diags.HasError() || len(urls) != 1 || urls[0] != "https://example.com/layer" {
+		t.Errorf("expected foreign layer URLs, got %v", layers[1].URLs)
+	}
+	if got := layers[1].Annotations.Elements()["owner"]; got == nil || got.String() != `"vendor"` {
+		t.Errorf("expected layer annotations, got %v", layers[1].Annotations)
+	}
+	if got := data.SchemaVersion.ValueInt64(); got != 2 {
+		t.Errorf("expected schema version 2, got %d", got)
+	}
+}
+
+const testAccImageManifestDataSourceConfig = `
+data "crane_image_manifest" "test" {
+  reference = "%s"
+}
+`
@@ -0,0 +1,254 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ImagePlatformsDataSource{}
+
+// ImagePlatformsDataSource lists the platforms of an image.
+type ImagePlatformsDataSource struct {
+	client *registryClient
+}
+
+// ImagePlatformsDataSourceModel describes the data source model.
+type ImagePlatformsDataSourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Reference         types.String `tfsdk:"reference"`
+	ResolvedReference types.String `tfsdk:"resolved_reference"`
+	Digest            types.String `tfsdk:"digest"`
+	Platforms         types.List   `tfsdk:"platforms"`
+}
+
+// imagePlatformModel describes a platform of an image and the manifest implementing it.
+type imagePlatformModel struct {
+	Platform     types.String `tfsdk:"platform"`
+	OS           types.String `tfsdk:"os"`
+	Architecture types.String `tfsdk:"architecture"`
+	Variant      types.String `tfsdk:"variant"`
+	OSVersion    types.String `tfsdk:"os_version"`
+	OSFeatures   types.List   `tfsdk:"os_features"`
+	Features     types.List   `tfsdk:"features"`
+	Digest       types.String `tfsdk:"digest"`
+	Size         types.Int64  `tfsdk:"size"`
+	MediaType    types.String `tfsdk:"media_type"`
+}
+
+var imagePlatformAttrTypes = map[string]attr.Type{
+	"platform":     types.StringType,
+	"os":           types.StringType,
+	"architecture": types.StringType,
+	"variant":      types.StringType,
+	"os_version":   types.StringType,
+	"os_features":  types.ListType{ElemType: types.StringType},
+	"features":     types.ListType{ElemType: types.StringType},
+	"digest":       types.StringType,
+	"size":         types.Int64Type,
+	"media_type":   types.StringType,
+}
+
+func NewImagePlatformsDataSource() datasource.DataSource {
+	return &ImagePlatformsDataSource{}
+}
+
+func (d *ImagePlatformsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_image_platforms"
+}
+
+func (d *ImagePlatformsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := platformSchemaAttributes()
+	attributes["platform"] = schema.StringAttribute{
+		MarkdownDescription: "The platform in the form os/arch[/variant][:osversion], as accepted by the `platform` attribute of `crane_image`.",
+		Computed:            true,
+	}
+	attributes["digest"] = schema.StringAttribute{
+		MarkdownDescription: "Content digest of the platform's manifest.",
+		Computed:            true,
+	}
+	attributes["size"] = schema.Int64Attribute{
+		MarkdownDescription: "Size of the platform's manifest in bytes.",
+		Computed:            true,
+	}
+	attributes["media_type"] = schema.StringAttribute{
+		MarkdownDescription: "Media type of the platform's manifest.",
+		Computed:            true,
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "List the platforms a container image is available for.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Equivalent to the requested reference.",
+				Computed:            true,
+			},
+			"reference": schema.StringAttribute{
+				MarkdownDescription: "A tag or digest identifying the image to inspect (for example `registry/repository:tag`).",
+				Required:            true,
+			},
+			"resolved_reference": schema.StringAttribute{
+				MarkdownDescription: "The location the image was actually read from. Differs from `reference` when the image was read through a provider `mirror`.",
+				Computed:            true,
+			},
+			"digest": schema.StringAttribute{
+				MarkdownDescription: "Content digest of the referenced image or index.",
+				Computed:            true,
+			},
+			"platforms": schema.ListNestedAttribute{
+				MarkdownDescription: "Platforms of the image, in index order. Entries without a platform and attestation manifests (with an `unknown` OS or architecture) are omitted. An image that is not an index has a single platform, read from its config.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: attributes,
+				},
+			},
+		},
+	}
+}
+
+func (d *ImagePlatformsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*registryClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *registryClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ImagePlatformsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ImagePlatformsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ref := data.Reference.ValueString()
+	options := append([]crane.Option{}, d.client.options...)
+	options = append(options, crane.WithContext(ctx))
+
+	resolved, err := d.client.resolveReference(ctx, ref, options)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading platforms for %q", ref), err.Error())
+		return
+	}
+
+	o := crane.GetOptions(options...)
+	parsed, err := name.ParseReference(resolved, o.Name...)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading platforms for %q", ref), err.Error())
+		return
+	}
+	desc, err := remote.Get(parsed, o.Remote...)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("reading platforms for %q", ref), err.Error())
+		return
+	}
+
+	var platforms []v1.Descriptor
+	switch {
+	case desc.MediaType.IsIndex():
+		index, err := desc.ImageIndex()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("reading platforms for %q", ref), err.Error())
+			return
+		}
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("parsing index manifest for %q", ref), err.Error())
+			return
+		}
+		platforms = imagePlatforms(manifest)
+	default:
+		img, err := desc.Image()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("reading platforms for %q", ref), err.Error())
+			return
+		}
+		config, err := img.ConfigFile()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("parsing config for %q", ref), err.Error())
+			return
+		}
+		platform := desc.Descriptor
+		platform.Platform = config.Platform()
+		platforms = []v1.Descriptor{platform}
+	}
+
+	data.ID = types.StringValue(ref)
+	data.ResolvedReference = types.StringValue(resolved)
+	data.Digest = types.StringValue(desc.Digest.String())
+	resp.Diagnostics.Append(data.setPlatforms(ctx, platforms)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// imagePlatforms returns the manifests of index that can be selected by platform, skipping
+// attestations, which are stored with an `unknown/unknown` platform.
+func imagePlatforms(index *v1.IndexManifest) []v1.Descriptor {
+	var platforms []v1.Descriptor
+	for _, manifest := range index.Manifests {
+		if manifest.Platform == nil || manifest.Platform.OS == "unknown" || manifest.Platform.Architecture == "unknown" {
+			continue
+		}
+		platforms = append(platforms, manifest)
+	}
+	return platforms
+}
+
+// setPlatforms records the platform manifests of the image in data.
+func (data *ImagePlatformsDataSourceModel) setPlatforms(ctx context.Context, manifests []v1.Descriptor) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	platforms := make([]attr.Value, 0, len(manifests))
+	for _, manifest := range manifests {
+		if manifest.Platform == nil {
+			continue
+		}
+		osFeatures, d := types.ListValueFrom(ctx, types.StringType, manifest.Platform.OSFeatures)
+		diags.Append(d...)
+		features, d := types.ListValueFrom(ctx, types.StringType, manifest.Platform.Features)
+		diags.Append(d...)
+		value, d := types.ObjectValueFrom(ctx, imagePlatformAttrTypes, imagePlatformModel{
+			Platform:     types.StringValue(manifest.Platform.String()),
+			OS:           types.StringValue(manifest.Platform.OS),
+			Architecture: types.StringValue(manifest.Platform.Architecture),
+			Variant:      types.StringValue(manifest.Platform.Variant),
+			OSVersion:    types.StringValue(manifest.Platform.OSVersion),
+			OSFeatures:   osFeatures,
+			Features:     features,
+			Digest:       types.StringValue(manifest.Digest.String()),
+			Size:         types.Int64Value(manifest.Size),
+			MediaType:    types.StringValue(string(manifest.MediaType)),
+		})
+		diags.Append(d...)
+		platforms = append(platforms, value)
+	}
+
+	var d diag.Diagnostics
+	data.Platforms, d = types.ListValue(types.ObjectType{AttrTypes: imagePlatformAttrTypes}, platforms)
+	diags.Append(d...)
+	return diags
+}
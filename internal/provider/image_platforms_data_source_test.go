@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	testutils "github.com/adam-tylr/terraform-provider-crane/testing"
+	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccImagePlatformsDataSource(t *testing.T) {
+	imageRef := testutils.CreateSourceRef("docker/library/alpine:latest")
+	arm64Digest, err := crane.Digest(imageRef, crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"}))
+	if err != nil {
+		t.Fatalf("failed to read digest for %s: %v", imageRef, err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccImagePlatformsDataSourceConfig, imageRef),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.crane_image_platforms.test",
+						tfjsonpath.New("platforms"),
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.ObjectPartial(map[string]knownvalue.Check{
+								"platform":     knownvalue.StringExact("linux/amd64"),
+								"architecture": knownvalue.StringExact("amd64"),
+							}),
+							knownvalue.ObjectPartial(map[string]knownvalue.Check{
+								"platform":     knownvalue.StringExact("linux/arm64"),
+								"architecture": knownvalue.StringExact("arm64"),
+								"digest":       knownvalue.StringExact(arm64Digest),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImagePlatformsDataSourceSinglePlatform(t *testing.T) {
+	imageRef := testutils.CreateSourceRef("docker/library/busybox:latest")
+	digest, err := crane.Digest(imageRef)
+	if err != nil {
+		t.Fatalf("failed to read digest for %s: %v", imageRef, err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccImagePlatformsDataSourceConfig, imageRef),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.crane_image_platforms.test",
+						tfjsonpath.New("platforms"),
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.ObjectPartial(map[string]knownvalue.Check{
+								"platform": knownvalue.StringExact("linux/amd64"),
+								"digest":   knownvalue.StringExact(digest),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestImagePlatforms(t *testing.T) {
+	index := &v1.IndexManifest{
+		Manifests: []v1.Descriptor{
+			{Digest: v1.Hash{Algorithm: "sha256", Hex: "a1"}, Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}},
+			{Digest: v1.Hash{Algorithm: "sha256", Hex: "a2"}, Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"}},
+			{Digest: v1.Hash{Algorithm: "sha256", Hex: "a3"}, Platform: &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+			{Digest: v1.Hash{Algorithm: "sha256", Hex: "a4"}},
+		},
+	}
+
+	platforms := imagePlatforms(index)
+	if len(platforms) != 2 {
+		t.Fatalf("expected 2 platforms, got %d: %v", len(platforms), platforms)
+	}
+	if platforms[0].Digest.Hex != "a1" || platforms[1].Digest.Hex != "a3" {
+		t.Errorf("expected platforms in index order without attestations, got %v", platforms)
+	}
+
+	var data ImagePlatformsDataSourceModel
+	if diags := data.setPlatforms(t.Context(), platforms); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	var models []imagePlatformModel
+	if diags := data.Platforms.ElementsAs(t.Context(), &models, false); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if got := models[1].Platform.ValueString(); got != "linux/arm64/v8" {
+		t.Errorf("expected platform string linux/arm64/v8, got %s", got)
+	}
+}
+
+const testAccImagePlatformsDataSourceConfig = `
+data "crane_image_platforms" "test" {
+  reference = "%s"
+}
+`
@@ -0,0 +1,391 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	testutils "github.com/adam-tylr/terraform-provider-crane/testing"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+// createFaultySource copies a seeded source image to a repository owned by the test, so
+// that faults can be injected into it without affecting other tests.
+func createFaultySource(t *testing.T) string {
+	t.Helper()
+
+	repo, teardown := testutils.CreateRepository(t)
+	t.Cleanup(teardown)
+
+	source := fmt.Sprintf("%s:latest", repo)
+	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:latest"), source); err != nil {
+		t.Fatalf("failed to copy source image: %v", err)
+	}
+	return source
+}
+
+func TestAccImageResourceSourceUnauthorized(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := createFaultySource(t)
+	testutils.InjectFaults(t, source, testutils.Fault{Path: "manifests/", Status: http.StatusUnauthorized})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImage(source, fmt.Sprintf("%s:latest", repo)),
+				ExpectError: regexp.MustCompile("Error reading source image"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceSourceManifestTruncated(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := createFaultySource(t)
+	testutils.InjectFaults(t, source, testutils.Fault{Method: http.MethodGet, Path: "manifests/", TruncateBody: true})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImage(source, fmt.Sprintf("%s:latest", repo)),
+				ExpectError: regexp.MustCompile("Error reading source image"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceSourceBlobTruncated(t *testing.T) {
+	first, teardownFirst := testutils.CreateRepository(t)
+	defer teardownFirst()
+	second, teardownSecond := testutils.CreateRepository(t)
+	defer teardownSecond()
+
+	// Layers of multiple destinations are read through the layer cache rather than mounted
+	// from the source repository, so they are fetched from the source.
+	source := createFaultySource(t)
+	testutils.InjectFaults(t, source, testutils.Fault{Method: http.MethodGet, Path: "blobs/", TruncateBody: true})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImageWithDestinations(
+					source,
+					fmt.Sprintf("%s:latest", first),
+					fmt.Sprintf("%s:latest", second),
+				),
+				ExpectError: regexp.MustCompile("Error pushing image to destination"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceSourceServerError(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := createFaultySource(t)
+	testutils.InjectFaults(t, source, testutils.Fault{Path: "manifests/", Status: http.StatusInternalServerError})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// The source keeps failing once the attempts are exhausted.
+				Config: `
+provider "crane" {
+  retry {
+    max_attempts    = 2
+    initial_backoff = "10ms"
+  }
+}
+` + testAccImage(source, fmt.Sprintf("%s:latest", repo)),
+				ExpectError: regexp.MustCompile("Error reading source image"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDestinationCheckForbidden(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	testutils.InjectFaults(t, repo, testutils.Fault{Method: http.MethodGet, Path: "manifests/", Status: http.StatusForbidden})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImage(testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo)),
+				ExpectError: regexp.MustCompile("Error checking destination repository"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDestinationUploadUnauthorized(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	// Blobs of the source image already exist in the registry, so report them missing to
+	// force an upload.
+	testutils.InjectFaults(t, repo,
+		testutils.Fault{Method: http.MethodHead, Path: "blobs/", Status: http.StatusNotFound},
+		testutils.Fault{Method: http.MethodPost, Path: "blobs/uploads/", Status: http.StatusUnauthorized},
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImage(testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo)),
+				ExpectError: regexp.MustCompile("Error pushing image to destination"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDestinationUploadInterrupted(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	// Tarball layers cannot be mounted, so the upload is started and then rejected.
+	tarball := testutils.CreateLocalTarball(t, testutils.CreateSourceRef("docker/library/alpine:latest"))
+	testutils.InjectFaults(t, repo,
+		testutils.Fault{Method: http.MethodHead, Path: "blobs/", Status: http.StatusNotFound},
+		testutils.Fault{Method: http.MethodPatch, Path: "blobs/uploads/", Status: http.StatusForbidden},
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImage(tarball, fmt.Sprintf("%s:latest", repo)),
+				ExpectError: regexp.MustCompile("Error pushing image to destination"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDestinationDigestMismatch(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	destination := fmt.Sprintf("%s:latest", repo)
+	if err := crane.Copy(source, destination); err != nil {
+		t.Fatalf("failed to seed repository with initial image: %v", err)
+	}
+	testutils.InjectFaults(t, repo, testutils.Fault{Method: http.MethodHead, Path: "manifests/", WrongDigest: true})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImage(source, destination),
+				ExpectError: regexp.MustCompile("Destination image already exists but does not match source"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceReadForbidden(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	config := testAccImage(testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					testutils.InjectFaults(t, repo, testutils.Fault{Method: http.MethodGet, Path: "manifests/", Status: http.StatusForbidden, Times: 1})
+				},
+				RefreshState: true,
+				ExpectError:  regexp.MustCompile("Error fetching image from registry"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceReadDigestError(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	config := testAccImage(testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				// Both the HEAD request for the digest and the GET request it falls back on fail.
+				PreConfig: func() {
+					testutils.InjectFaults(t, repo,
+						testutils.Fault{Method: http.MethodHead, Path: "manifests/latest", Status: http.StatusForbidden, Times: 1},
+						testutils.Fault{Method: http.MethodGet, Path: "manifests/latest", Status: http.StatusForbidden, Times: 1},
+					)
+				},
+				RefreshState: true,
+				ExpectError:  regexp.MustCompile("Error reading image digest"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDeleteTagForbidden(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	config := testAccImageWithDeleteOnDestroy(testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo), deleteModeTag, false)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					testutils.InjectFaults(t, repo, testutils.Fault{Method: http.MethodDelete, Path: "manifests/", Status: http.StatusForbidden, Times: 1})
+				},
+				Config:      config,
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("Error deleting tag"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDeleteManifestTagsForbidden(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	config := testAccImageWithDeleteOnDestroy(testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo), deleteModeManifest, false)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					testutils.InjectFaults(t, repo, testutils.Fault{Method: http.MethodGet, Path: "tags/list", Status: http.StatusForbidden, Times: 1})
+				},
+				Config:      config,
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("Error checking tags referencing image"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceThrottled(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	digest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+	testutils.InjectFaults(t, repo,
+		testutils.Fault{Path: "manifests/", Status: http.StatusTooManyRequests, RetryAfter: "0", Times: 2},
+		testutils.Fault{Latency: 50 * time.Millisecond},
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "crane" {
+  retry {
+    initial_backoff = "10ms"
+  }
+}
+` + testAccImage(source, fmt.Sprintf("%s:latest", repo)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(digest),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceServiceUnavailable(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	digest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+	// Responses without a Retry-After header are retried after the configured backoff.
+	testutils.InjectFaults(t, repo, testutils.Fault{Method: http.MethodPut, Path: "manifests/", Status: http.StatusServiceUnavailable, Times: 2})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "crane" {
+  retry {
+    initial_backoff = "10ms"
+  }
+}
+` + testAccImage(source, fmt.Sprintf("%s:latest", repo)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(digest),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+			},
+		},
+	})
+}
@@ -4,19 +4,33 @@
 package provider
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
 
 	testutils "github.com/adam-tylr/terraform-provider-crane/testing"
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 func TestAccImageResourceRemoteImage(t *testing.T) {
@@ -98,7 +112,7 @@ func TestAccImageResourceRemoteImage(t *testing.T) {
 			},
 			// Update Source digest
 			{
-				Config: testAccImage(testutils.CreateSourceRef("docker/library/alpine@sha256:4b7ce07002c69e8f3d704a9c5d6fd3053be500b7f1c69fc0d80990c2ad8dd412"), fmt.Sprintf("%s:latest", repo)),
+				Config: testAccImage(testutils.CreateSourceDigestRef(t, "docker/library/alpine:3.21"), fmt.Sprintf("%s:latest", repo)),
 				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
@@ -113,7 +127,7 @@ func TestAccImageResourceRemoteImage(t *testing.T) {
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
 						tfjsonpath.New("source"),
-						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine@sha256:4b7ce07002c69e8f3d704a9c5d6fd3053be500b7f1c69fc0d80990c2ad8dd412")),
+						knownvalue.StringExact(testutils.CreateSourceDigestRef(t, "docker/library/alpine:3.21")),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
@@ -247,40 +261,69 @@ func TestAccImageResourceSourceDigest(t *testing.T) {
 	})
 }
 
-func TestAccImageResourceWithTarball(t *testing.T) {
-	repo, teardown := testutils.CreateRepository(t)
-	defer teardown()
-	tarPath := testutils.CreateLocalTarball(t, testutils.CreateSourceRef("docker/library/alpine:latest"))
-	updatedTarPath := testutils.CreateLocalTarball(t, testutils.CreateSourceRef("nginx/nginx:latest"))
-	defer os.Remove(tarPath)
-	defer os.Remove(updatedTarPath)
+func TestAccImageResourceSourceTagMoved(t *testing.T) {
+	sourceRepo, teardownSource := testutils.CreateRepository(t)
+	defer teardownSource()
+	destinationRepo, teardownDestination := testutils.CreateRepository(t)
+	defer teardownDestination()
+
+	sourceLatest := fmt.Sprintf("%s:latest", sourceRepo)
+	sourceUpdate := fmt.Sprintf("%s:update", sourceRepo)
+	destination := fmt.Sprintf("%s:latest", destinationRepo)
+
+	if err := crane.Copy(testutils.CreateSourceRef("nginx/nginx:latest"), sourceLatest); err != nil {
+		t.Fatalf("failed to seed source latest image: %v", err)
+	}
+	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:3"), sourceUpdate); err != nil {
+		t.Fatalf("failed to seed source update image: %v", err)
+	}
+
+	sourceDigest, err := crane.Digest(sourceLatest)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+	updatedSourceDigest, err := crane.Digest(sourceUpdate)
+	if err != nil {
+		t.Fatalf("failed to read updated source digest: %v", err)
+	}
+	retagged := testutils.CreateSourceRef("docker/library/alpine:3.21")
+	retaggedDigest, err := crane.Digest(retagged)
+	if err != nil {
+		t.Fatalf("failed to read retagged digest: %v", err)
+	}
+
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
-			// Create and Read testing
 			{
-				Config: testAccImageWithPlatform(tarPath, fmt.Sprintf("%s:latest", repo), "linux/amd64"),
+				Config: testAccImage(sourceLatest, destination),
 				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("id"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("reference"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(sourceDigest),
 					),
+				},
+			},
+			// Moving the source tag plans an update without source_digest being set
+			{
+				PreConfig: func() {
+					if err := crane.Copy(sourceUpdate, sourceLatest); err != nil {
+						t.Fatalf("failed to update source latest image: %v", err)
+					}
+				},
+				Config: testAccImage(sourceLatest, destination),
+				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("source"),
-						knownvalue.StringExact(tarPath),
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(updatedSourceDigest),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("destination"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("pushed_digest"),
+						knownvalue.StringExact(updatedSourceDigest),
 					),
 					testutils.CheckRemoteImage("crane_image.test"),
 				},
@@ -288,206 +331,414 @@ func TestAccImageResourceWithTarball(t *testing.T) {
 					PreApply: []plancheck.PlanCheck{
 						plancheck.ExpectResourceAction(
 							"crane_image.test",
-							plancheck.ResourceActionCreate,
+							plancheck.ResourceActionUpdate,
+						),
+						plancheck.ExpectUnknownValue(
+							"crane_image.test",
+							tfjsonpath.New("digest"),
 						),
 					},
 				},
 			},
-			// Update Source path
+			// Retagging the destination by hand does not push the unchanged source again
 			{
-				Config: testAccImageWithPlatform(updatedTarPath, fmt.Sprintf("%s:latest", repo), "linux/amd64"),
+				PreConfig: func() {
+					if err := crane.Copy(retagged, destination); err != nil {
+						t.Fatalf("failed to retag destination: %v", err)
+					}
+				},
+				Config: testAccImage(sourceLatest, destination),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
 				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("id"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("reference"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("source"),
-						knownvalue.StringExact(updatedTarPath),
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(retaggedDigest),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("destination"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("pushed_digest"),
+						knownvalue.StringExact(updatedSourceDigest),
 					),
-					testutils.CheckRemoteImage("crane_image.test"),
-				},
-				ConfigPlanChecks: resource.ConfigPlanChecks{
-					PreApply: []plancheck.PlanCheck{
-						plancheck.ExpectResourceAction(
-							"crane_image.test",
-							plancheck.ResourceActionUpdate,
-						),
-					},
 				},
 			},
+			{
+				Config:   testAccImage(sourceLatest, destination),
+				PlanOnly: true,
+			},
 		},
 	})
 }
 
-func TestAccImageResourceWithPlatform(t *testing.T) {
-	repo, teardown := testutils.CreateRepository(t)
-	defer teardown()
+func TestAccImageResourceDriftMode(t *testing.T) {
+	sourceRepo, teardownSource := testutils.CreateRepository(t)
+	defer teardownSource()
+	destinationRepo, teardownDestination := testutils.CreateRepository(t)
+	defer teardownDestination()
+
+	source := fmt.Sprintf("%s:latest", sourceRepo)
+	rollback := fmt.Sprintf("%s:rollback", sourceRepo)
+	destination := fmt.Sprintf("%s:prod", destinationRepo)
+
+	if err := crane.Copy(testutils.CreateSourceRef("nginx/nginx:latest"), source); err != nil {
+		t.Fatalf("failed to seed source image: %v", err)
+	}
+	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:3"), rollback); err != nil {
+		t.Fatalf("failed to seed rollback image: %v", err)
+	}
+	sourceDigest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+	rollbackDigest, err := crane.Digest(rollback)
+	if err != nil {
+		t.Fatalf("failed to read rollback digest: %v", err)
+	}
+	retag := func() {
+		if err := crane.Copy(rollback, destination); err != nil {
+			t.Fatalf("failed to retag destination: %v", err)
+		}
+	}
+
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
-			// Create and Read testing
 			{
-				Config: testAccImageWithPlatform(testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo), "linux/amd64"),
+				Config: testAccImageWithDriftMode(source, destination, driftModeAdopt),
 				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("id"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("reference"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("source"),
-						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine:latest")),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("destination"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("platform"),
-						knownvalue.StringExact("linux/amd64"),
+						tfjsonpath.New("observed_digest"),
+						knownvalue.StringExact(sourceDigest),
 					),
-					testutils.CheckRemoteImage("crane_image.test"),
 				},
+			},
+			// A rollback outside of Terraform is kept, and recorded apart from digest
+			{
+				PreConfig: retag,
+				Config:    testAccImageWithDriftMode(source, destination, driftModeAdopt),
 				ConfigPlanChecks: resource.ConfigPlanChecks{
 					PreApply: []plancheck.PlanCheck{
-						plancheck.ExpectResourceAction(
-							"crane_image.test",
-							plancheck.ResourceActionCreate,
-						),
+						plancheck.ExpectEmptyPlan(),
 					},
 				},
-			},
-			// Update the platform to a different architecture
-			{
-				Config: testAccImageWithPlatform(testutils.CreateSourceRef("docker/library/alpine"), fmt.Sprintf("%s:latest", repo), "linux/arm64"),
 				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("id"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("reference"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("source"),
-						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine")),
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(sourceDigest),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("destination"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("observed_digest"),
+						knownvalue.StringExact(rollbackDigest),
 					),
+				},
+			},
+			// Enforcing the desired state pushes the source again
+			{
+				Config: testAccImageWithDriftMode(source, destination, driftModeEnforce),
+				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("platform"),
-						knownvalue.StringExact("linux/arm64"),
+						tfjsonpath.New("observed_digest"),
+						knownvalue.StringExact(sourceDigest),
 					),
 					testutils.CheckRemoteImage("crane_image.test"),
 				},
+			},
+			{
+				PreConfig: retag,
+				Config:    testAccImageWithDriftMode(source, destination, driftModeEnforce),
 				ConfigPlanChecks: resource.ConfigPlanChecks{
 					PreApply: []plancheck.PlanCheck{
 						plancheck.ExpectResourceAction(
 							"crane_image.test",
 							plancheck.ResourceActionUpdate,
 						),
+						plancheck.ExpectUnknownValue(
+							"crane_image.test",
+							tfjsonpath.New("observed_digest"),
+						),
 					},
 				},
-			},
-			// Nullify the platform
-			{
-				Config: testAccImage(testutils.CreateSourceRef("docker/library/alpine"), fmt.Sprintf("%s:latest", repo)),
 				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("id"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("reference"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("source"),
-						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine")),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("destination"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
-					),
-					statecheck.ExpectKnownValue(
-						"crane_image.test",
-						tfjsonpath.New("platform"),
-						knownvalue.Null(),
+						tfjsonpath.New("observed_digest"),
+						knownvalue.StringExact(sourceDigest),
 					),
 					testutils.CheckRemoteImage("crane_image.test"),
 				},
-				ConfigPlanChecks: resource.ConfigPlanChecks{
-					PreApply: []plancheck.PlanCheck{
-						plancheck.ExpectResourceAction(
-							"crane_image.test",
-							plancheck.ResourceActionUpdate,
-						),
-					},
-				},
 			},
 		},
 	})
 }
 
-func TestAccImageResourceExternalDeletion(t *testing.T) {
-	repo, teardown := testutils.CreateRepository(t)
-	defer teardown()
+func TestAccImageResourceDriftModeUnset(t *testing.T) {
+	sourceRepo, teardownSource := testutils.CreateRepository(t)
+	defer teardownSource()
+	destinationRepo, teardownDestination := testutils.CreateRepository(t)
+	defer teardownDestination()
+
+	source := fmt.Sprintf("%s:latest", sourceRepo)
+	rollback := fmt.Sprintf("%s:rollback", sourceRepo)
+	destination := fmt.Sprintf("%s:prod", destinationRepo)
+
+	if err := crane.Copy(testutils.CreateSourceRef("nginx/nginx:latest"), source); err != nil {
+		t.Fatalf("failed to seed source image: %v", err)
+	}
+	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:3"), rollback); err != nil {
+		t.Fatalf("failed to seed rollback image: %v", err)
+	}
+	sourceDigest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+	rollbackDigest, err := crane.Digest(rollback)
+	if err != nil {
+		t.Fatalf("failed to read rollback digest: %v", err)
+	}
+
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
-			// Create and Read testing
 			{
-				Config: testAccImage(testutils.CreateSourceRef("docker/library/alpine"), fmt.Sprintf("%s:latest", repo)),
+				Config: testAccImage(source, destination),
 				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("id"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(sourceDigest),
 					),
+				},
+			},
+			// A rollback outside of Terraform is adopted as digest without a warning or an update
+			{
+				PreConfig: func() {
+					if err := crane.Copy(rollback, destination); err != nil {
+						t.Fatalf("failed to retag destination: %v", err)
+					}
+				},
+				Config: testAccImage(source, destination),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("reference"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(rollbackDigest),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("source"),
-						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine")),
+						tfjsonpath.New("observed_digest"),
+						knownvalue.StringExact(rollbackDigest),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("pushed_digest"),
+						knownvalue.StringExact(sourceDigest),
+					),
+				},
+			},
+			{
+				Config:   testAccImage(source, destination),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDriftModeUnsetAdditionalTags(t *testing.T) {
+	sourceRepo, teardownSource := testutils.CreateRepository(t)
+	defer teardownSource()
+	destinationRepo, teardownDestination := testutils.CreateRepository(t)
+	defer teardownDestination()
+
+	source := fmt.Sprintf("%s:latest", sourceRepo)
+	rollback := fmt.Sprintf("%s:rollback", sourceRepo)
+	destination := fmt.Sprintf("%s:prod", destinationRepo)
+	stable := fmt.Sprintf("%s:stable", destinationRepo)
+
+	if err := crane.Copy(testutils.CreateSourceRef("nginx/nginx:latest"), source); err != nil {
+		t.Fatalf("failed to seed source image: %v", err)
+	}
+	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:3"), rollback); err != nil {
+		t.Fatalf("failed to seed rollback image: %v", err)
+	}
+	sourceDigest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+	rollbackDigest, err := crane.Digest(rollback)
+	if err != nil {
+		t.Fatalf("failed to read rollback digest: %v", err)
+	}
+	config := testAccImageWithAdditionalTags(source, destination, "stable")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			// The additional tags still point at the pushed image after a rollback, so nothing drifts
+			{
+				PreConfig: func() {
+					if err := crane.Copy(rollback, destination); err != nil {
+						t.Fatalf("failed to retag destination: %v", err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(rollbackDigest),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("tag_digests").AtMapKey(stable),
+						knownvalue.StringExact(sourceDigest),
+					),
+				},
+			},
+			// Moving an additional tag back only retags it, keeping the rollback in place
+			{
+				PreConfig: func() {
+					if err := crane.Copy(rollback, stable); err != nil {
+						t.Fatalf("failed to move additional tag: %v", err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("crane_image.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: func(s *terraform.State) error {
+					for ref, want := range map[string]string{destination: rollbackDigest, stable: sourceDigest} {
+						digest, err := crane.Digest(ref)
+						if err != nil {
+							return err
+						}
+						if digest != want {
+							return fmt.Errorf("%s = %s, want %s", ref, digest, want)
+						}
+					}
+					return nil
+				},
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestImageResourceModelDriftedDestinations(t *testing.T) {
+	expected := "sha256:" + strings.Repeat("a", 64)
+	other := "sha256:" + strings.Repeat("b", 64)
+
+	data := ImageResourceModel{
+		Id:                 types.StringValue("registry.local/app:prod"),
+		Digest:             types.StringValue(expected),
+		ObservedDigest:     types.StringValue(other),
+		DestinationResults: types.MapNull(types.ObjectType{AttrTypes: destinationResultAttrTypes}),
+	}
+	drifted, diags := data.driftedDestinations(t.Context())
+	if diags.HasError() {
+		t.Fatalf("driftedDestinations() diagnostics = %v", diags)
+	}
+	if len(drifted) != 1 || drifted["registry.local/app:prod"] != other {
+		t.Errorf("driftedDestinations() = %v, want registry.local/app:prod holding %s", drifted, other)
+	}
+
+	results := map[string]destinationResultModel{
+		"registry.local/app:prod": {
+			Status:          types.StringValue(destinationStatusUnchanged),
+			Digest:          types.StringValue(expected),
+			PinnedReference: types.StringValue("registry.local/app@" + expected),
+			Error:           types.StringNull(),
+		},
+		"registry.local/app:stable": {
+			Status:          types.StringValue(destinationStatusUnchanged),
+			Digest:          types.StringValue(other),
+			PinnedReference: types.StringValue("registry.local/app@" + other),
+			Error:           types.StringNull(),
+		},
+		// A destination skipped for a conflict is adopted, not drifted.
+		"registry.local/app:skipped": {
+			Status:          types.StringValue(destinationStatusSkipped),
+			Digest:          types.StringValue(other),
+			PinnedReference: types.StringValue("registry.local/app@" + other),
+			Error:           types.StringNull(),
+		},
+	}
+	resultMap, d := types.MapValueFrom(t.Context(), types.ObjectType{AttrTypes: destinationResultAttrTypes}, results)
+	if d.HasError() {
+		t.Fatalf("failed to build destination results: %v", d)
+	}
+	data = ImageResourceModel{
+		Id:                 types.StringValue("registry.local/app:prod,registry.local/app:stable"),
+		Digest:             types.StringValue(expected),
+		ObservedDigest:     types.StringNull(),
+		DestinationResults: resultMap,
+	}
+	drifted, diags = data.driftedDestinations(t.Context())
+	if diags.HasError() {
+		t.Fatalf("driftedDestinations() diagnostics = %v", diags)
+	}
+	if len(drifted) != 1 || drifted["registry.local/app:stable"] != other {
+		t.Errorf("driftedDestinations() = %v, want registry.local/app:stable holding %s", drifted, other)
+	}
+}
+
+func TestAccImageResourceWithTarball(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	tarPath := testutils.CreateLocalTarball(t, testutils.CreateSourceRef("docker/library/alpine:latest"))
+	updatedTarPath := testutils.CreateLocalTarball(t, testutils.CreateSourceRef("nginx/nginx:latest"))
+	defer os.Remove(tarPath)
+	defer os.Remove(updatedTarPath)
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccImageWithPlatform(tarPath, fmt.Sprintf("%s:latest", repo), "linux/amd64"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("reference"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("source"),
+						knownvalue.StringExact(tarPath),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
@@ -505,16 +756,85 @@ func TestAccImageResourceExternalDeletion(t *testing.T) {
 					},
 				},
 			},
+			// Update Source path
 			{
-				// Delete the image externally and expect the resource to be recreated
-				PreConfig: func() {
-					// Delete the image from the remote repository
-					testutils.DeleteRemoteImage(t, repo, "latest")
+				Config: testAccImageWithPlatform(updatedTarPath, fmt.Sprintf("%s:latest", repo), "linux/amd64"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("reference"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("source"),
+						knownvalue.StringExact(updatedTarPath),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
 				},
-				RefreshState:       true,
-				ExpectNonEmptyPlan: true,
-				RefreshPlanChecks: resource.RefreshPlanChecks{
-					PostRefresh: []plancheck.PlanCheck{
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceWithPlatform(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccImageWithPlatform(testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo), "linux/amd64"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("reference"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("source"),
+						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine:latest")),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("platform"),
+						knownvalue.StringExact("linux/amd64"),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
 						plancheck.ExpectResourceAction(
 							"crane_image.test",
 							plancheck.ResourceActionCreate,
@@ -522,151 +842,1531 @@ func TestAccImageResourceExternalDeletion(t *testing.T) {
 					},
 				},
 			},
+			// Update the platform to a different architecture
+			{
+				Config: testAccImageWithPlatform(testutils.CreateSourceRef("docker/library/alpine"), fmt.Sprintf("%s:latest", repo), "linux/arm64"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("reference"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("source"),
+						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine")),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("platform"),
+						knownvalue.StringExact("linux/arm64"),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+			},
+			// Nullify the platform
+			{
+				Config: testAccImage(testutils.CreateSourceRef("docker/library/alpine"), fmt.Sprintf("%s:latest", repo)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("reference"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("source"),
+						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine")),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("platform"),
+						knownvalue.Null(),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceSinglePlatformSource(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	source := testutils.CreateSourceRef("docker/library/busybox:latest")
+	sourceDigest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read digest for %s: %v", source, err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// A source that is not an index is copied as is
+			{
+				Config: testAccImage(source, fmt.Sprintf("%s:latest", repo)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(sourceDigest),
+					),
+				},
+			},
+			// Selecting the platform of the image keeps the same manifest
+			{
+				Config: testAccImageWithPlatform(source, fmt.Sprintf("%s:latest", repo), "linux/amd64"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(sourceDigest),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceExternalDeletion(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccImage(testutils.CreateSourceRef("docker/library/alpine"), fmt.Sprintf("%s:latest", repo)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("reference"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("source"),
+						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine")),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionCreate,
+						),
+					},
+				},
+			},
+			{
+				// Delete the image externally and expect the resource to be recreated
+				PreConfig: func() {
+					// Delete the image from the remote repository
+					testutils.DeleteRemoteImage(t, repo, "latest")
+				},
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				RefreshPlanChecks: resource.RefreshPlanChecks{
+					PostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionCreate,
+						),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceExternalRepoDeletion(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccImage(testutils.CreateSourceRef("docker/library/alpine"), fmt.Sprintf("%s:latest", repo)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("reference"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("source"),
+						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine")),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination"),
+						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionCreate,
+						),
+					},
+				},
+			},
+			{
+				// Delete the image externally and expect the resource to be recreated
+				PreConfig: func() {
+					teardown()
+				},
+				RefreshState:       true,
+				ExpectNonEmptyPlan: true,
+				RefreshPlanChecks: resource.RefreshPlanChecks{
+					PostRefresh: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionCreate,
+						),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceImport(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	tags := testutils.CopyImagesToRepository(t, repo)
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImage(testutils.CreateSourceRef(fmt.Sprintf("nginx/nginx:%s", tags[0])), fmt.Sprintf("%s:%s", repo, tags[0])),
+			},
+			{
+				ResourceName:            "crane_image.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"source"},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceImportWithSource(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	tags := testutils.CopyImagesToRepository(t, repo)
+	source := testutils.CreateSourceRef(fmt.Sprintf("nginx/nginx:%s", tags[0]))
+	destination := fmt.Sprintf("%s:%s", repo, tags[0])
+	digest, err := crane.Digest(destination)
+	if err != nil {
+		t.Fatalf("failed to read destination digest: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImage(source, destination),
+			},
+			{
+				ResourceName:      "crane_image.test",
+				ImportState:       true,
+				ImportStateId:     destination + "," + source,
+				ImportStateVerify: true,
+			},
+			// Importing with an import block plans no changes
+			{
+				Config:          testAccImage(source, destination),
+				ResourceName:    "crane_image.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithID,
+				ImportStateId:   destination + "," + source,
+				ImportPlanChecks: resource.ImportPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("crane_image.test", plancheck.ResourceActionNoop),
+					},
+				},
+			},
+			{
+				Config: testAccImage(source, destination),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentity("crane_image.test", map[string]knownvalue.Check{
+						"destination": knownvalue.StringExact(destination),
+					}),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(digest),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceImportByIdentity(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	tags := testutils.CopyImagesToRepository(t, repo)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImage(testutils.CreateSourceRef(fmt.Sprintf("nginx/nginx:%s", tags[0])), fmt.Sprintf("%s:%s", repo, tags[0])),
+			},
+			{
+				ResourceName:            "crane_image.test",
+				ImportState:             true,
+				ImportStateKind:         resource.ImportBlockWithResourceIdentity,
+				ImportStateVerifyIgnore: []string{"source"},
+			},
+		},
+	})
+}
+
+func TestParseImageImportID(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		id   string
+		want imageImport
+	}{
+		{
+			id:   "registry.local/app:prod",
+			want: imageImport{destination: "registry.local/app:prod"},
+		},
+		{
+			id:   "registry.local/app@" + digest,
+			want: imageImport{destination: "registry.local/app@" + digest, digest: digest},
+		},
+		{
+			id:   "registry.local/app:prod,docker.io/library/nginx:1.27",
+			want: imageImport{destination: "registry.local/app:prod", source: "docker.io/library/nginx:1.27"},
+		},
+		{
+			id:   "registry.local/app:prod, docker.io/library/nginx:1.27, linux/arm64/v8",
+			want: imageImport{destination: "registry.local/app:prod", source: "docker.io/library/nginx:1.27", platform: "linux/arm64/v8"},
+		},
+		{
+			id:   "oci-layout:///bundle@" + digest + ",oci-layout:///source:app",
+			want: imageImport{destination: "oci-layout:///bundle@" + digest, source: "oci-layout:///source:app", digest: digest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := parseImageImportID(tt.id)
+			if err != nil {
+				t.Fatalf("parseImageImportID() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseImageImportID() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, id := range []string{"", ",source", "registry.local/app:prod,", "registry.local/App", "a/b:c,d,e,f", "oci-layout://"} {
+		if _, err := parseImageImportID(id); err == nil {
+			t.Errorf("parseImageImportID(%q) succeeded, want an error", id)
+		}
+	}
+}
+
+func TestAccImageResourceSourceRepositoryDoesNotExist(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	missingSource := testutils.CreateSourceRef(fmt.Sprintf("missing/%s:latest", strings.ToLower(t.Name())))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImage(missingSource, fmt.Sprintf("%s:latest", repo)),
+				ExpectError: regexp.MustCompile("Error reading source image"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDestinationRepositoryDoesNotExist(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		t.Fatalf("unexpected repository format: %s", repo)
+	}
+	missingRepo := fmt.Sprintf("%s/missing-%s", parts[0], strings.ToLower(t.Name()))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImage(
+					testutils.CreateSourceRef("docker/library/alpine:latest"),
+					fmt.Sprintf("%s:latest", missingRepo),
+				),
+				ExpectError: regexp.MustCompile("Error pushing image to destination"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDestinationTagAlreadyExistsWithDifferentDigest(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	destination := fmt.Sprintf("%s:latest", repo)
+	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:3"), destination); err != nil {
+		t.Fatalf("failed to seed repository with initial image: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImage(testutils.CreateSourceRef("nginx/nginx:latest"), destination),
+				ExpectError: regexp.MustCompile("Destination image already exists but does not match source"),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceOnConflict(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := testutils.CreateSourceRef("nginx/nginx:latest")
+	existing := testutils.CreateSourceRef("docker/library/alpine:3")
+	skipped := fmt.Sprintf("%s:skipped", repo)
+	backedUp := fmt.Sprintf("%s:backed-up", repo)
+	for _, destination := range []string{skipped, backedUp} {
+		if err := crane.Copy(existing, destination); err != nil {
+			t.Fatalf("failed to seed repository with initial image: %v", err)
+		}
+	}
+	existingDigest, err := crane.Digest(existing)
+	if err != nil {
+		t.Fatalf("failed to read existing digest: %v", err)
+	}
+	sourceDigest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImageWithOnConflict(source, skipped, onConflictSkip) + testAccImageWithOnConflict(source, backedUp, onConflictBackup),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.skip",
+						tfjsonpath.New("observed_digest"),
+						knownvalue.StringExact(existingDigest),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.backup",
+						tfjsonpath.New("observed_digest"),
+						knownvalue.StringExact(sourceDigest),
+					),
+				},
+				Check: func(s *terraform.State) error {
+					tags, err := crane.ListTags(repo)
+					if err != nil {
+						return err
+					}
+					for _, tag := range tags {
+						if strings.HasPrefix(tag, "backed-up-prev-") {
+							digest, err := crane.Digest(fmt.Sprintf("%s:%s", repo, tag))
+							if err != nil {
+								return err
+							}
+							if digest != existingDigest {
+								return fmt.Errorf("backup tag %s = %s, want %s", tag, digest, existingDigest)
+							}
+							return nil
+						}
+					}
+					return fmt.Errorf("no backup tag found in %v", tags)
+				},
+			},
+			// The skipped destination is adopted rather than planned to be pushed again
+			{
+				Config: testAccImageWithOnConflict(source, skipped, onConflictSkip) + testAccImageWithOnConflict(source, backedUp, onConflictBackup),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.skip",
+						tfjsonpath.New("pushed_digest"),
+						knownvalue.StringExact(sourceDigest),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceOnConflictAddedDestination(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := testutils.CreateSourceRef("nginx/nginx:latest")
+	existing := testutils.CreateSourceRef("docker/library/alpine:3")
+	destination := fmt.Sprintf("%s:latest", repo)
+	added := fmt.Sprintf("%s:added", repo)
+	if err := crane.Copy(existing, added); err != nil {
+		t.Fatalf("failed to seed repository with initial image: %v", err)
+	}
+	existingDigest, err := crane.Digest(existing)
+	if err != nil {
+		t.Fatalf("failed to read existing digest: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImageWithDestinations(source, destination),
+			},
+			// A destination added on update is a conflict like any destination on create.
+			{
+				Config: testAccImageWithDestinations(source, destination, added),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination_results").AtMapKey(added).AtMapKey("status"),
+						knownvalue.StringExact(destinationStatusFailed),
+					),
+				},
+				Check: func(s *terraform.State) error {
+					digest, err := crane.Digest(added)
+					if err != nil {
+						return err
+					}
+					if digest != existingDigest {
+						return fmt.Errorf("added destination holds %s, want %s left in place", digest, existingDigest)
+					}
+					return nil
+				},
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccImageResourceOnConflictSkipEnforced(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := testutils.CreateSourceRef("nginx/nginx:latest")
+	existing := testutils.CreateSourceRef("docker/library/alpine:3")
+	skipped := fmt.Sprintf("%s:skipped", repo)
+	skippedInSet := fmt.Sprintf("%s:skipped-in-set", repo)
+	pushed := fmt.Sprintf("%s:pushed", repo)
+	for _, destination := range []string{skipped, skippedInSet} {
+		if err := crane.Copy(existing, destination); err != nil {
+			t.Fatalf("failed to seed repository with initial image: %v", err)
+		}
+	}
+	existingDigest, err := crane.Digest(existing)
+	if err != nil {
+		t.Fatalf("failed to read existing digest: %v", err)
+	}
+	config := testAccImageWithOnConflictSkipEnforced(source, skipped, skippedInSet, pushed)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.single",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(existingDigest),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.set",
+						tfjsonpath.New("destination_results").AtMapKey(skippedInSet).AtMapKey("status"),
+						knownvalue.StringExact(destinationStatusSkipped),
+					),
+				},
+			},
+			// Even with drift_mode enforced, skipped destinations are not pushed again
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: func(s *terraform.State) error {
+					for _, destination := range []string{skipped, skippedInSet} {
+						digest, err := crane.Digest(destination)
+						if err != nil {
+							return err
+						}
+						if digest != existingDigest {
+							return fmt.Errorf("skipped destination %s = %s, want %s", destination, digest, existingDigest)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceOCILayout(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	sourceDigest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+	bundle := ociLayoutScheme + t.TempDir()
+	destination := fmt.Sprintf("%s:from-layout", repo)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Write the multi-architecture index to a layout, then push it from the layout.
+			{
+				Config: testAccImageWithOCILayout(source, bundle+":alpine", destination),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.bundle",
+						tfjsonpath.New("pinned_reference"),
+						knownvalue.StringExact(bundle+"@"+sourceDigest),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.bundle",
+						tfjsonpath.New("repository"),
+						knownvalue.StringExact(bundle),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.bundle",
+						tfjsonpath.New("tag"),
+						knownvalue.StringExact("alpine"),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(sourceDigest),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+			},
+			{
+				Config: testAccImageWithOCILayout(source, bundle+":alpine", destination),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDeleteOnDestroyTag(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	destination := fmt.Sprintf("%s:latest", repo)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := crane.Digest(destination); err == nil {
+				return fmt.Errorf("expected tag %s to be deleted", destination)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImageWithDeleteOnDestroy(testutils.CreateSourceRef("docker/library/alpine:latest"), destination, "tag", false),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("delete_on_destroy"),
+						knownvalue.StringExact("tag"),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDeleteOnDestroyManifest(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	destination := fmt.Sprintf("%s:latest", repo)
+	other := fmt.Sprintf("%s:other", repo)
+
+	var digest string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := crane.Digest(fmt.Sprintf("%s@%s", repo, digest)); err == nil {
+				return fmt.Errorf("expected image %s@%s to be deleted", repo, digest)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImageWithDeleteOnDestroy(source, destination, "manifest", false),
+				Check: func(*terraform.State) error {
+					var err error
+					digest, err = crane.Digest(destination)
+					return err
+				},
+			},
+			// Refuse to delete a digest that another tag still references
+			{
+				PreConfig: func() {
+					if err := crane.Tag(destination, "other"); err != nil {
+						t.Fatalf("failed to tag %s as %s: %v", destination, other, err)
+					}
+				},
+				Config:      testAccImageWithDeleteOnDestroy(source, destination, "manifest", false),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("Image is still referenced by other tags"),
+			},
+			// Forcing the deletion lets the final destroy remove the manifest
+			{
+				Config: testAccImageWithDeleteOnDestroy(source, destination, "manifest", true),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("force_delete"),
+						knownvalue.Bool(true),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDeleteOnDestroyAdditionalTags(t *testing.T) {
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	digest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+
+	for _, mode := range []string{deleteModeTag, deleteModeManifest} {
+		t.Run(mode, func(t *testing.T) {
+			repo, teardown := testutils.CreateRepository(t)
+			defer teardown()
+			destination := fmt.Sprintf("%s:1.4.2", repo)
+
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy: func(*terraform.State) error {
+					if mode == deleteModeManifest {
+						if _, err := crane.Digest(fmt.Sprintf("%s@%s", repo, digest)); err == nil {
+							return fmt.Errorf("expected image %s@%s to be deleted", repo, digest)
+						}
+						return nil
+					}
+					for _, tag := range []string{"1.4.2", "1.4", "latest"} {
+						if _, err := crane.Digest(fmt.Sprintf("%s:%s", repo, tag)); err == nil {
+							return fmt.Errorf("expected tag %s:%s to be deleted", repo, tag)
+						}
+					}
+					return nil
+				},
+				Steps: []resource.TestStep{
+					// The additional tags are deleted along with the destination, and do not
+					// prevent the manifest from being deleted.
+					{
+						Config: testAccImageWithAdditionalTagsDeleteOnDestroy(source, destination, mode, "1.4", "latest"),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestAccImageResourceDeleteOnDestroyManifestSharedRepository(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	digest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := crane.Digest(fmt.Sprintf("%s@%s", repo, digest)); err == nil {
+				return fmt.Errorf("expected image %s@%s to be deleted", repo, digest)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			// The destinations sharing a repository do not prevent each other from being deleted.
+			{
+				Config: testAccImageWithDestinationsDeleteOnDestroy(source, "manifest", fmt.Sprintf("%s:stable", repo), fmt.Sprintf("%s:latest", repo)),
+			},
+		},
+	})
+}
+
+func TestAccImageResourceDeleteOnDestroyManifestDestinationsChanged(t *testing.T) {
+	first, teardownFirst := testutils.CreateRepository(t)
+	defer teardownFirst()
+	second, teardownSecond := testutils.CreateRepository(t)
+	defer teardownSecond()
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	rollback := testutils.CreateSourceRef("docker/library/alpine:3")
+	digest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+	rollbackDigest, err := crane.Digest(rollback)
+	if err != nil {
+		t.Fatalf("failed to read rollback digest: %v", err)
+	}
+	config := testAccImageWithDestinationsDeleteOnDestroy(source, "manifest", fmt.Sprintf("%s:latest", first), fmt.Sprintf("%s:latest", second))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			for _, ref := range []string{fmt.Sprintf("%s@%s", first, digest), fmt.Sprintf("%s@%s", second, rollbackDigest)} {
+				if _, err := crane.Digest(ref); err == nil {
+					return fmt.Errorf("expected image %s to be deleted", ref)
+				}
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			// Each destination deletes the image found there, even if it was changed outside of
+			// Terraform.
+			{
+				PreConfig: func() {
+					if err := crane.Copy(rollback, fmt.Sprintf("%s:latest", second)); err != nil {
+						t.Fatalf("failed to retag destination: %v", err)
+					}
+				},
+				Config: config,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination_results").AtMapKey(fmt.Sprintf("%s:latest", second)).AtMapKey("digest"),
+						knownvalue.StringExact(rollbackDigest),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestTagsReferencingDigest(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	repo := strings.TrimPrefix(server.URL, "http://") + "/app"
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	for _, tag := range []string{"latest", "stable", "other"} {
+		if err := crane.Push(img, fmt.Sprintf("%s:%s", repo, tag)); err != nil {
+			t.Fatalf("failed to seed image: %v", err)
+		}
+	}
+
+	ref, err := name.ParseReference(repo + ":latest")
+	if err != nil {
+		t.Fatalf("failed to parse reference: %v", err)
+	}
+	got, err := tagsReferencingDigest(ref, digest.String(), []string{repo + ":latest", repo + ":stable", "registry.local/app:other"}, crane.GetOptions())
+	if err != nil {
+		t.Fatalf("tagsReferencingDigest() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != "other" {
+		t.Errorf("tagsReferencingDigest() = %v, want [other]", got)
+	}
+}
+
+func TestPinSource(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	repo := strings.TrimPrefix(server.URL, "http://") + "/app"
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	if err := crane.Push(img, repo+":latest"); err != nil {
+		t.Fatalf("failed to seed image: %v", err)
+	}
+
+	pinned, err := pinSource(repo+":latest", repo+":latest", nil)
+	if err != nil {
+		t.Fatalf("pinSource() error = %v", err)
+	}
+	if want := repo + "@" + digest.String(); pinned != want {
+		t.Errorf("pinSource() = %s, want %s", pinned, want)
+	}
+
+	// The pinned source is still the verified image once the tag has moved.
+	other, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	if err := crane.Push(other, repo+":latest"); err != nil {
+		t.Fatalf("failed to move tag: %v", err)
+	}
+	if _, got, err := loadSource(pinned, nil, ""); err != nil || got != digest.String() {
+		t.Errorf("loadSource(%s) = %s, %v, want %s", pinned, got, err, digest)
+	}
+
+	if got, err := pinSource(pinned, pinned, nil); err != nil || got != pinned {
+		t.Errorf("pinSource(%s) = %s, %v, want it unchanged", pinned, got, err)
+	}
+}
+
+func TestAccImageResourceMultipleDestinations(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	otherRepo, otherTeardown := testutils.CreateRepository(t)
+	defer otherTeardown()
+
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	first := fmt.Sprintf("%s:latest", repo)
+	second := fmt.Sprintf("%s:latest", otherRepo)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImageWithDestinations(source, first, second),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destinations"),
+						knownvalue.SetExact([]knownvalue.Check{
+							knownvalue.StringExact(first),
+							knownvalue.StringExact(second),
+						}),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination_results").AtMapKey(first).AtMapKey("status"),
+						knownvalue.StringExact("pushed"),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination_results").AtMapKey(second).AtMapKey("status"),
+						knownvalue.StringExact("pushed"),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("reference"),
+						knownvalue.Null(),
+					),
+				},
+			},
+			// Deleting one destination externally pushes it again
+			{
+				PreConfig: func() {
+					testutils.DeleteRemoteImage(t, otherRepo, "latest")
+				},
+				Config: testAccImageWithDestinations(source, first, second),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceMultipleDestinationsPartialFailure(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	failingRepo, teardownFailing := testutils.CreateRepository(t)
+	defer teardownFailing()
+
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	destination := fmt.Sprintf("%s:latest", repo)
+	failing := fmt.Sprintf("%s:latest", failingRepo)
+	removeFaults := testutils.InjectFaults(t, failingRepo, testutils.Fault{Method: http.MethodPut, Path: "manifests/", Status: http.StatusForbidden})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The failed destination is reported without tainting the resource.
+			{
+				Config: testAccImageWithDestinations(source, destination, failing),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destinations"),
+						knownvalue.SetExact([]knownvalue.Check{knownvalue.StringExact(destination), knownvalue.StringExact(failing)}),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination_results").AtMapKey(destination).AtMapKey("status"),
+						knownvalue.StringExact(destinationStatusPushed),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination_results").AtMapKey(failing).AtMapKey("status"),
+						knownvalue.StringExact(destinationStatusFailed),
+					),
+				},
+				ExpectNonEmptyPlan: true,
+			},
+			// The next apply retries the failed destination in place.
+			{
+				PreConfig: removeFaults,
+				Config:    testAccImageWithDestinations(source, destination, failing),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("crane_image.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("destination_results").AtMapKey(failing).AtMapKey("status"),
+						knownvalue.StringExact(destinationStatusPushed),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccImageResourceMultipleDestinationsAllFailed(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		t.Fatalf("unexpected repository format: %s", repo)
+	}
+	missingRepo := fmt.Sprintf("%s/missing-%s", parts[0], strings.ToLower(t.Name()))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImageWithDestinations(
+					testutils.CreateSourceRef("docker/library/alpine:latest"),
+					fmt.Sprintf("%s:missing", missingRepo),
+					fmt.Sprintf("%s:latest", missingRepo),
+				),
+				ExpectError: regexp.MustCompile("Error pushing image to destination"),
+			},
 		},
 	})
 }
 
-func TestAccImageResourceExternalRepoDeletion(t *testing.T) {
+func TestAccImageResourceAdditionalTags(t *testing.T) {
 	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	destination := fmt.Sprintf("%s:1.4.2", repo)
+	digest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
-			// Create and Read testing
 			{
-				Config: testAccImage(testutils.CreateSourceRef("docker/library/alpine"), fmt.Sprintf("%s:latest", repo)),
+				Config: testAccImageWithAdditionalTags(source, destination, "1.4", "latest"),
 				ConfigStateChecks: []statecheck.StateCheck{
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("id"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("pinned_reference"),
+						knownvalue.StringExact(fmt.Sprintf("%s@%s", repo, digest)),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("reference"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("repository"),
+						knownvalue.StringExact(repo),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("source"),
-						knownvalue.StringExact(testutils.CreateSourceRef("docker/library/alpine")),
+						tfjsonpath.New("tag"),
+						knownvalue.StringExact("1.4.2"),
 					),
 					statecheck.ExpectKnownValue(
 						"crane_image.test",
-						tfjsonpath.New("destination"),
-						knownvalue.StringExact(fmt.Sprintf("%s:latest", repo)),
+						tfjsonpath.New("tag_digests"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							fmt.Sprintf("%s:1.4", repo):    knownvalue.StringExact(digest),
+							fmt.Sprintf("%s:latest", repo): knownvalue.StringExact(digest),
+						}),
 					),
-					testutils.CheckRemoteImage("crane_image.test"),
-				},
-				ConfigPlanChecks: resource.ConfigPlanChecks{
-					PreApply: []plancheck.PlanCheck{
-						plancheck.ExpectResourceAction(
-							"crane_image.test",
-							plancheck.ResourceActionCreate,
-						),
-					},
 				},
 			},
+			// Moving a tag outside of Terraform retags it without pushing again
 			{
-				// Delete the image externally and expect the resource to be recreated
 				PreConfig: func() {
-					teardown()
+					if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:3"), fmt.Sprintf("%s:latest", repo)); err != nil {
+						t.Fatalf("failed to move tag: %v", err)
+					}
 				},
-				RefreshState:       true,
-				ExpectNonEmptyPlan: true,
-				RefreshPlanChecks: resource.RefreshPlanChecks{
-					PostRefresh: []plancheck.PlanCheck{
+				Config: testAccImageWithAdditionalTags(source, destination, "1.4", "latest"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
 						plancheck.ExpectResourceAction(
 							"crane_image.test",
-							plancheck.ResourceActionCreate,
+							plancheck.ResourceActionUpdate,
 						),
 					},
 				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("tag_digests").AtMapKey(fmt.Sprintf("%s:latest", repo)),
+						knownvalue.StringExact(digest),
+					),
+				},
 			},
 		},
 	})
 }
 
-func TestAccImageResourceImport(t *testing.T) {
+func TestAccImageResourceMutate(t *testing.T) {
 	repo, teardown := testutils.CreateRepository(t)
 	defer teardown()
-	tags := testutils.CopyImagesToRepository(t, repo)
+
+	source := testutils.CreateSourceRef("docker/library/alpine:latest")
+	destination := fmt.Sprintf("%s:latest", repo)
+	sourceDigest, err := crane.Digest(source)
+	if err != nil {
+		t.Fatalf("failed to read source digest: %v", err)
+	}
+
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccImage(testutils.CreateSourceRef(fmt.Sprintf("nginx/nginx:%s", tags[0])), fmt.Sprintf("%s:%s", repo, tags[0])),
+				Config: testAccImageWithMutate(source, destination, "nobody"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringFunc(func(digest string) error {
+							if digest == sourceDigest {
+								return fmt.Errorf("expected mutated digest to differ from source digest %s", sourceDigest)
+							}
+							return nil
+						}),
+					),
+					testutils.CheckRemoteImage("crane_image.test"),
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					func(s *terraform.State) error {
+						cfg, err := crane.Config(destination, crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"}))
+						if err != nil {
+							return err
+						}
+						if !strings.Contains(string(cfg), `"User":"nobody"`) || !strings.Contains(string(cfg), `"org.opencontainers.image.source":"https://example.com/app"`) {
+							return fmt.Errorf("expected mutated config, got %s", cfg)
+						}
+						return nil
+					},
+				),
 			},
 			{
-				ResourceName:            "crane_image.test",
-				ImportState:             true,
-				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"source"},
+				// Changing the mutation pushes a new image to the same destination.
+				Config: testAccImageWithMutate(source, destination, "1000:1000"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_image.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					func(s *terraform.State) error {
+						cfg, err := crane.Config(destination)
+						if err != nil {
+							return err
+						}
+						if !strings.Contains(string(cfg), `"User":"1000:1000"`) {
+							return fmt.Errorf("expected updated user, got %s", cfg)
+						}
+						return nil
+					},
+				),
 			},
 		},
 	})
 }
 
-func TestAccImageResourceSourceRepositoryDoesNotExist(t *testing.T) {
-	repo, teardown := testutils.CreateRepository(t)
+func TestImageResourceModelSetReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		destination string
+		pinned      string
+		repository  string
+		tag         types.String
+	}{
+		{
+			destination: "registry.local:5000/team/app:1.4.2",
+			pinned:      "registry.local:5000/team/app@" + digest,
+			repository:  "registry.local:5000/team/app",
+			tag:         types.StringValue("1.4.2"),
+		},
+		{
+			destination: "registry.local:5000/app",
+			pinned:      "registry.local:5000/app@" + digest,
+			repository:  "registry.local:5000/app",
+			tag:         types.StringValue("latest"),
+		},
+		{
+			destination: "registry.local/app@" + digest,
+			pinned:      "registry.local/app@" + digest,
+			repository:  "registry.local/app",
+			tag:         types.StringNull(),
+		},
+		{
+			destination: "oci-layout:///bundle/images:app",
+			pinned:      "oci-layout:///bundle/images@" + digest,
+			repository:  "oci-layout:///bundle/images",
+			tag:         types.StringValue("app"),
+		},
+		{
+			destination: "oci-layout://bundle",
+			pinned:      "oci-layout://bundle@" + digest,
+			repository:  "oci-layout://bundle",
+			tag:         types.StringValue("latest"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			var data ImageResourceModel
+			data.setReference(tt.destination, digest, crane.GetOptions())
+			if got := data.Reference.ValueString(); got != tt.destination {
+				t.Errorf("reference = %q, want %q", got, tt.destination)
+			}
+			if got := data.PinnedReference.ValueString(); got != tt.pinned {
+				t.Errorf("pinned_reference = %q, want %q", got, tt.pinned)
+			}
+			if got := data.Repository.ValueString(); got != tt.repository {
+				t.Errorf("repository = %q, want %q", got, tt.repository)
+			}
+			if !data.Tag.Equal(tt.tag) {
+				t.Errorf("tag = %s, want %s", data.Tag, tt.tag)
+			}
+		})
+	}
+}
+
+func TestImageResourceReadSourceDigest(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	source := strings.TrimPrefix(server.URL, "http://") + "/app:latest"
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	if err := crane.Push(img, source); err != nil {
+		t.Fatalf("failed to push image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	tarPath := filepath.Join(t.TempDir(), "image.tar")
+	if err := crane.Save(img, "app:latest", tarPath); err != nil {
+		t.Fatalf("failed to save image: %v", err)
+	}
+
+	r := &ImageResource{client: &registryClient{}}
+	for _, source := range []string{source, tarPath} {
+		got, err := r.readSourceDigest(t.Context(), &ImageResourceModel{Source: types.StringValue(source)})
+		if err != nil {
+			t.Fatalf("readSourceDigest(%s) error = %v", source, err)
+		}
+		if got != digest.String() {
+			t.Errorf("readSourceDigest(%s) = %s, want %s", source, got, digest)
+		}
+	}
+
+	// The digest of a mutated image is the one pushed rather than the source's.
+	data := &ImageResourceModel{
+		Source: types.StringValue(source),
+		Mutate: &mutateModel{
+			Labels:       types.MapValueMust(types.StringType, map[string]attr.Value{"team": types.StringValue("platform")}),
+			Annotations:  types.MapNull(types.StringType),
+			Env:          types.MapNull(types.StringType),
+			Entrypoint:   types.ListNull(types.StringType),
+			Cmd:          types.ListNull(types.StringType),
+			WorkingDir:   types.StringNull(),
+			User:         types.StringNull(),
+			ExposedPorts: types.SetNull(types.StringType),
+		},
+	}
+	got, err := r.readSourceDigest(t.Context(), data)
+	if err != nil {
+		t.Fatalf("readSourceDigest() with mutate error = %v", err)
+	}
+	if got == digest.String() {
+		t.Errorf("readSourceDigest() with mutate = %s, want the digest of the mutated image", got)
+	}
+}
+
+func TestAccImageResourceCopyReferrers(t *testing.T) {
+	sourceRepo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	destinationRepo, teardown := testutils.CreateRepository(t)
 	defer teardown()
 
-	missingSource := testutils.CreateSourceRef(fmt.Sprintf("missing/%s:latest", strings.ToLower(t.Name())))
+	source := fmt.Sprintf("%s:signed", sourceRepo)
+	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:latest"), source); err != nil {
+		t.Fatalf("failed to seed source image: %v", err)
+	}
+	sourceRef, err := name.ParseReference(source)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	desc, err := remote.Head(sourceRef)
+	if err != nil {
+		t.Fatalf("failed to read source: %v", err)
+	}
+	attestation := pushReferrer(t, sourceRef.Context(), *desc)
+	signature, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create signature: %v", err)
+	}
+	if err := remote.Write(cosignTag(sourceRef.Context(), desc.Digest, ".sig"), signature); err != nil {
+		t.Fatalf("failed to push signature: %v", err)
+	}
+	signatureDigest, err := signature.Digest()
+	if err != nil {
+		t.Fatalf("failed to read signature digest: %v", err)
+	}
+	destination := fmt.Sprintf("%s:signed", destinationRepo)
 
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccImage(missingSource, fmt.Sprintf("%s:latest", repo)),
-				ExpectError: regexp.MustCompile("Error reading source image"),
+				Config: testAccImageWithCopyReferrers(source, destination),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("referrer_digests"),
+						knownvalue.SetExact([]knownvalue.Check{
+							knownvalue.StringExact(attestation),
+							knownvalue.StringExact(signatureDigest.String()),
+						}),
+					),
+				},
+				Check: func(s *terraform.State) error {
+					ref, err := name.ParseReference(destination)
+					if err != nil {
+						return err
+					}
+					if _, err := remote.Head(cosignTag(ref.Context(), desc.Digest, ".sig")); err != nil {
+						return fmt.Errorf("expected the signature to be copied: %w", err)
+					}
+					if _, err := remote.Head(ref.Context().Digest(attestation)); err != nil {
+						return fmt.Errorf("expected the attestation to be copied: %w", err)
+					}
+					return nil
+				},
 			},
 		},
 	})
 }
 
-func TestAccImageResourceDestinationRepositoryDoesNotExist(t *testing.T) {
+func TestAccImageResourceCopyReferrersWithMutate(t *testing.T) {
 	repo, teardown := testutils.CreateRepository(t)
 	defer teardown()
 
-	parts := strings.SplitN(repo, "/", 2)
-	if len(parts) != 2 {
-		t.Fatalf("unexpected repository format: %s", repo)
-	}
-	missingRepo := fmt.Sprintf("%s/missing-%s", parts[0], strings.ToLower(t.Name()))
-
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccImage(
-					testutils.CreateSourceRef("docker/library/alpine:latest"),
-					fmt.Sprintf("%s:latest", missingRepo),
-				),
-				ExpectError: regexp.MustCompile("Error pushing image to destination"),
+				Config: fmt.Sprintf(`
+resource "crane_image" "test" {
+  source         = %q
+  destination    = %q
+  copy_referrers = true
+
+  mutate {
+    user = "nobody"
+  }
+}
+`, testutils.CreateSourceRef("docker/library/alpine:latest"), fmt.Sprintf("%s:latest", repo)),
+				ExpectError: regexp.MustCompile("Invalid copy_referrers"),
 			},
 		},
 	})
 }
 
-func TestAccImageResourceDestinationTagAlreadyExistsWithDifferentDigest(t *testing.T) {
-	repo, teardown := testutils.CreateRepository(t)
+func TestAccImageResourceVerify(t *testing.T) {
+	sourceRepo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	destinationRepo, teardown := testutils.CreateRepository(t)
 	defer teardown()
 
-	destination := fmt.Sprintf("%s:latest", repo)
-	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:3"), destination); err != nil {
-		t.Fatalf("failed to seed repository with initial image: %v", err)
+	source := fmt.Sprintf("%s:signed", sourceRepo)
+	if err := crane.Copy(testutils.CreateSourceRef("docker/library/alpine:latest"), source); err != nil {
+		t.Fatalf("failed to seed source image: %v", err)
+	}
+	sourceRef, err := name.ParseReference(source)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	desc, err := remote.Head(sourceRef)
+	if err != nil {
+		t.Fatalf("failed to read source: %v", err)
+	}
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
 	}
+	signImage(t, sourceRef.Context(), desc.Digest, signer)
+	destination := fmt.Sprintf("%s:signed", destinationRepo)
 
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccImage(testutils.CreateSourceRef("nginx/nginx:latest"), destination),
-				ExpectError: regexp.MustCompile("Destination image already exists but does not match source"),
+				// A signature made with another key fails the plan.
+				Config:      testAccImageWithVerify(source, destination, encodePublicKey(t, other)),
+				ExpectError: regexp.MustCompile(`Source image signature verification failed`),
+			},
+			{
+				Config: testAccImageWithVerify(source, destination, encodePublicKey(t, other), encodePublicKey(t, signer)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_image.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(desc.Digest.String()),
+					),
+				},
 			},
 		},
 	})
@@ -681,6 +2381,58 @@ resource "crane_image" "test" {
 `, source, destination)
 }
 
+func testAccImageWithDriftMode(source string, destination string, driftMode string) string {
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source = %q
+  destination = %q
+  drift_mode = %q
+}
+`, source, destination, driftMode)
+}
+
+func testAccImageWithOnConflict(source string, destination string, onConflict string) string {
+	return fmt.Sprintf(`
+resource "crane_image" %[3]q {
+  source = %[1]q
+  destination = %[2]q
+  on_conflict = %[3]q
+}
+`, source, destination, onConflict)
+}
+
+func testAccImageWithOnConflictSkipEnforced(source string, skipped string, skippedInSet string, pushed string) string {
+	return fmt.Sprintf(`
+resource "crane_image" "single" {
+  source = %[1]q
+  destination = %[2]q
+  on_conflict = "skip"
+  drift_mode = "enforce"
+}
+
+resource "crane_image" "set" {
+  source = %[1]q
+  destinations = [%[3]q, %[4]q]
+  on_conflict = "skip"
+  drift_mode = "enforce"
+}
+`, source, skipped, skippedInSet, pushed)
+}
+
+func testAccImageWithOCILayout(source string, layout string, destination string) string {
+	return fmt.Sprintf(`
+resource "crane_image" "bundle" {
+  source = %[1]q
+  destination = %[2]q
+}
+
+resource "crane_image" "test" {
+  source = crane_image.bundle.pinned_reference
+  destination = %[3]q
+}
+`, source, layout, destination)
+}
+
 func testAccImageWithPlatform(source string, destination string, platform string) string {
 	return fmt.Sprintf(`
 resource "crane_image" "test" {
@@ -700,3 +2452,113 @@ resource "crane_image" "test" {
 }
 `, source, destination, sourceDigest)
 }
+
+func testAccImageWithDeleteOnDestroy(source string, destination string, mode string, force bool) string {
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source = %q
+  destination = %q
+  delete_on_destroy = %q
+  force_delete = %t
+}
+`, source, destination, mode, force)
+}
+
+func testAccImageWithDestinations(source string, destinations ...string) string {
+	quoted := make([]string, 0, len(destinations))
+	for _, destination := range destinations {
+		quoted = append(quoted, fmt.Sprintf("%q", destination))
+	}
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source = %q
+  destinations = [%s]
+}
+`, source, strings.Join(quoted, ", "))
+}
+
+func testAccImageWithDestinationsDeleteOnDestroy(source string, mode string, destinations ...string) string {
+	quoted := make([]string, 0, len(destinations))
+	for _, destination := range destinations {
+		quoted = append(quoted, fmt.Sprintf("%q", destination))
+	}
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source = %q
+  destinations = [%s]
+  delete_on_destroy = %q
+}
+`, source, strings.Join(quoted, ", "), mode)
+}
+
+func testAccImageWithAdditionalTags(source string, destination string, tags ...string) string {
+	quoted := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		quoted = append(quoted, fmt.Sprintf("%q", tag))
+	}
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source = %q
+  destination = %q
+  additional_tags = [%s]
+}
+`, source, destination, strings.Join(quoted, ", "))
+}
+
+func testAccImageWithAdditionalTagsDeleteOnDestroy(source string, destination string, mode string, tags ...string) string {
+	quoted := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		quoted = append(quoted, fmt.Sprintf("%q", tag))
+	}
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source = %q
+  destination = %q
+  additional_tags = [%s]
+  delete_on_destroy = %q
+}
+`, source, destination, strings.Join(quoted, ", "), mode)
+}
+
+func testAccImageWithMutate(source string, destination string, user string) string {
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source = %q
+  destination = %q
+
+  mutate {
+    labels = {
+      "org.opencontainers.image.source" = "https://example.com/app"
+    }
+    user = %q
+  }
+}
+`, source, destination, user)
+}
+
+func testAccImageWithCopyReferrers(source string, destination string) string {
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source         = %q
+  destination    = %q
+  copy_referrers = true
+}
+`, source, destination)
+}
+
+func testAccImageWithVerify(source string, destination string, publicKeys ...string) string {
+	keys := make([]string, 0, len(publicKeys))
+	for _, key := range publicKeys {
+		keys = append(keys, fmt.Sprintf("%q", key))
+	}
+	return fmt.Sprintf(`
+resource "crane_image" "test" {
+  source      = %q
+  destination = %q
+
+  verify {
+    public_keys = [%s]
+  }
+}
+`, source, destination, strings.Join(keys, ", "))
+}
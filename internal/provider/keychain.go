@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// registryAuthModel describes a registry_auth block in the provider configuration.
+type registryAuthModel struct {
+	Address       types.String  `tfsdk:"address"`
+	Username      types.String  `tfsdk:"username"`
+	Password      types.String  `tfsdk:"password"`
+	IdentityToken types.String  `tfsdk:"identity_token"`
+	ECR           *ecrAuthModel `tfsdk:"ecr"`
+}
+
+// registryKeychain resolves credentials configured in the provider, keyed by registry host.
+// Registries without configured credentials resolve to anonymous so a multi-keychain can
+// fall through to the next keychain.
+type registryKeychain map[string]authn.Authenticator
+
+func (k registryKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	if auth, ok := k[target.RegistryStr()]; ok {
+		return auth, nil
+	}
+	return authn.Anonymous, nil
+}
+
+// registryHost normalizes a registry address such as `https://index.docker.io/v1/` or
+// `docker.io` to the host name used by go-containerregistry when resolving credentials.
+func registryHost(address string) (string, error) {
+	host := strings.TrimPrefix(address, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	host, _, _ = strings.Cut(host, "/")
+
+	registry, err := name.NewRegistry(host)
+	if err != nil {
+		return "", err
+	}
+	return registry.RegistryStr(), nil
+}
+
+// authenticator converts a registry_auth block into static or ECR credentials for host.
+func (m registryAuthModel) authenticator(host string) (authn.Authenticator, error) {
+	switch {
+	case m.ECR != nil:
+		if !m.Username.IsNull() || !m.Password.IsNull() || !m.IdentityToken.IsNull() {
+			return nil, fmt.Errorf("username, password and identity_token cannot be combined with ecr")
+		}
+		return newECRAuthenticator(host, *m.ECR)
+	case !m.IdentityToken.IsNull():
+		if !m.Password.IsNull() {
+			return nil, fmt.Errorf("only one of password or identity_token may be set")
+		}
+		return authn.FromConfig(authn.AuthConfig{
+			Username:      m.Username.ValueString(),
+			IdentityToken: m.IdentityToken.ValueString(),
+		}), nil
+	case !m.Username.IsNull() && !m.Password.IsNull():
+		return &authn.Basic{
+			Username: m.Username.ValueString(),
+			Password: m.Password.ValueString(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("either username and password, identity_token or ecr must be set")
+	}
+}
+
+// newRegistryKeychain builds a keychain from the configured registry_auth blocks.
+func newRegistryKeychain(auths []registryAuthModel) (registryKeychain, error) {
+	keychain := registryKeychain{}
+	for _, auth := range auths {
+		address := auth.Address.ValueString()
+		host, err := registryHost(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry address %q: %w", address, err)
+		}
+		if _, ok := keychain[host]; ok {
+			return nil, fmt.Errorf("duplicate registry_auth block for registry %q", host)
+		}
+		authenticator, err := auth.authenticator(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials for registry %q: %w", address, err)
+		}
+		keychain[host] = authenticator
+	}
+	return keychain, nil
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRegistryKeychain(t *testing.T) {
+	keychain, err := newRegistryKeychain([]registryAuthModel{
+		{
+			Address:  types.StringValue("https://index.docker.io/v1/"),
+			Username: types.StringValue("user"),
+			Password: types.StringValue("secret"),
+		},
+		{
+			Address:       types.StringValue("registry.example.com:5000"),
+			IdentityToken: types.StringValue("token"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]authn.AuthConfig{
+		"alpine:latest":                        {Username: "user", Password: "secret"},
+		"registry.example.com:5000/app:latest": {IdentityToken: "token"},
+		"ghcr.io/org/app:latest":               {},
+	}
+	for ref, expected := range cases {
+		r, err := name.ParseReference(ref)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", ref, err)
+		}
+		auth, err := keychain.Resolve(r.Context())
+		if err != nil {
+			t.Fatalf("failed to resolve %q: %v", ref, err)
+		}
+		cfg, err := auth.Authorization()
+		if err != nil {
+			t.Fatalf("failed to authorize %q: %v", ref, err)
+		}
+		if *cfg != expected {
+			t.Errorf("unexpected credentials for %q: %+v", ref, *cfg)
+		}
+	}
+}
+
+func TestRegistryKeychainInvalid(t *testing.T) {
+	cases := map[string][]registryAuthModel{
+		"missing credentials": {
+			{Address: types.StringValue("ghcr.io"), Username: types.StringValue("user")},
+		},
+		"password and identity token": {
+			{
+				Address:       types.StringValue("ghcr.io"),
+				Username:      types.StringValue("user"),
+				Password:      types.StringValue("secret"),
+				IdentityToken: types.StringValue("token"),
+			},
+		},
+		"duplicate registry": {
+			{Address: types.StringValue("docker.io"), IdentityToken: types.StringValue("a")},
+			{Address: types.StringValue("index.docker.io"), IdentityToken: types.StringValue("b")},
+		},
+	}
+	for name, auths := range cases {
+		if _, err := newRegistryKeychain(auths); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package provider
+
+import (
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestParseLayoutReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		ref  string
+		want layoutReference
+	}{
+		{
+			ref:  "oci-layout:///bundle",
+			want: layoutReference{path: "/bundle"},
+		},
+		{
+			ref:  "oci-layout://bundle:app",
+			want: layoutReference{path: "bundle", tag: "app"},
+		},
+		{
+			ref:  "oci-layout://C:/bundle/images:1.4.2",
+			want: layoutReference{path: "C:/bundle/images", tag: "1.4.2"},
+		},
+		{
+			ref:  "oci-layout://./host:5000/images",
+			want: layoutReference{path: "./host:5000/images"},
+		},
+		{
+			ref:  "oci-layout:///bundle@" + digest,
+			want: layoutReference{path: "/bundle", digest: digest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := parseLayoutReference(tt.ref)
+			if err != nil {
+				t.Fatalf("parseLayoutReference() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseLayoutReference() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, ref := range []string{"oci-layout://", "oci-layout://bundle:", "oci-layout://bundle@sha256:abc", "oci-layout://:app"} {
+		if _, err := parseLayoutReference(ref); err == nil {
+			t.Errorf("parseLayoutReference(%q) succeeded, want an error", ref)
+		}
+	}
+}
+
+func TestLoadLayoutIndex(t *testing.T) {
+	amd64, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	arm64, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+	idxDigest, err := idx.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	armDigest, err := arm64.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "bundle")
+	source := ociLayoutScheme + dir + ":app"
+	status, d := pushLayoutDestination(t.Context(), idx, "source", idxDigest.String(), source, onConflictFail)
+	if d != nil || status != destinationStatusPushed {
+		t.Fatalf("pushLayoutDestination() = %s, %v, want %s", status, d, destinationStatusPushed)
+	}
+
+	loaded, digest, err := loadLayout(source, nil)
+	if err != nil {
+		t.Fatalf("loadLayout() error = %v", err)
+	}
+	if digest != idxDigest.String() {
+		t.Errorf("loadLayout() digest = %s, want %s", digest, idxDigest)
+	}
+	if _, ok := loaded.(v1.ImageIndex); !ok {
+		t.Errorf("loadLayout() = %T, want an image index", loaded)
+	}
+
+	// The only image of the layout is read when the reference has no tag.
+	if _, digest, err = loadLayout(ociLayoutScheme+dir, &v1.Platform{OS: "linux", Architecture: "arm64"}); err != nil {
+		t.Fatalf("loadLayout(linux/arm64) error = %v", err)
+	}
+	if digest != armDigest.String() {
+		t.Errorf("loadLayout(linux/arm64) digest = %s, want %s", digest, armDigest)
+	}
+
+	if _, _, err := loadLayout(source, &v1.Platform{OS: "windows", Architecture: "amd64"}); err == nil {
+		t.Error("loadLayout(windows/amd64) succeeded, want an error")
+	}
+	if _, _, err := loadLayout(ociLayoutScheme+dir+":missing", nil); !errors.Is(err, errLayoutNotFound) {
+		t.Errorf("loadLayout(missing) error = %v, want %v", err, errLayoutNotFound)
+	}
+}
+
+func TestPushLayoutDestinationExisting(t *testing.T) {
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	other, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	otherDigest, err := other.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+
+	dir := t.TempDir()
+	destination := ociLayoutScheme + dir + ":app"
+	if _, err := layoutDigest(destination); !errors.Is(err, errLayoutNotFound) {
+		t.Fatalf("layoutDigest() of an empty directory error = %v, want %v", err, errLayoutNotFound)
+	}
+	if status, d := pushLayoutDestination(t.Context(), other, "source", otherDigest.String(), destination, onConflictFail); d != nil || status != destinationStatusPushed {
+		t.Fatalf("pushLayoutDestination(other) = %s, %v, want %s", status, d, destinationStatusPushed)
+	}
+
+	status, d := pushLayoutDestination(t.Context(), other, "source", otherDigest.String(), destination, onConflictFail)
+	if d != nil || status != destinationStatusUnchanged {
+		t.Errorf("pushLayoutDestination(same) = %s, %v, want %s", status, d, destinationStatusUnchanged)
+	}
+
+	status, d = pushLayoutDestination(t.Context(), img, "source", digest.String(), destination, onConflictFail)
+	if d == nil || status != destinationStatusFailed {
+		t.Fatalf("pushLayoutDestination(different) = %s, %v, want a conflict", status, d)
+	}
+	if d.Summary() != "Destination image already exists but does not match source" {
+		t.Errorf("pushLayoutDestination(different) summary = %q", d.Summary())
+	}
+
+	status, d = pushLayoutDestination(t.Context(), img, "source", digest.String(), destination, onConflictSkip)
+	if d != nil || status != destinationStatusSkipped {
+		t.Errorf("pushLayoutDestination(different, skip) = %s, %v, want %s", status, d, destinationStatusSkipped)
+	}
+	if got, err := layoutDigest(destination); err != nil || got != otherDigest.String() {
+		t.Errorf("destination after skip = %s, %v, want %s", got, err, otherDigest)
+	}
+
+	status, d = pushLayoutDestination(t.Context(), img, "source", digest.String(), destination, onConflictBackup)
+	if d != nil || status != destinationStatusPushed {
+		t.Fatalf("pushLayoutDestination(different, backup) = %s, %v, want %s", status, d, destinationStatusPushed)
+	}
+	if got, err := layoutDigest(destination); err != nil || got != digest.String() {
+		t.Errorf("destination after backup = %s, %v, want %s", got, err, digest)
+	}
+	ref := layoutReference{path: dir, tag: "app"}
+	backups, err := layoutTagsReferencingDigest(ref, otherDigest.String(), nil)
+	if err != nil {
+		t.Fatalf("layoutTagsReferencingDigest() error = %v", err)
+	}
+	if len(backups) != 1 || !strings.HasPrefix(backups[0], "app-prev-") {
+		t.Errorf("backup tags = %v, want one app-prev-<timestamp> tag", backups)
+	}
+}
+
+func TestTagLayout(t *testing.T) {
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+
+	dir := t.TempDir()
+	ref := layoutReference{path: dir, tag: "1.4.2"}
+	if status, d := pushLayoutDestination(t.Context(), img, "source", digest.String(), ref.tagged(ref.tag), onConflictFail); d != nil || status != destinationStatusPushed {
+		t.Fatalf("pushLayoutDestination() = %s, %v, want %s", status, d, destinationStatusPushed)
+	}
+	if err := tagLayout(ref, digest.String(), []string{"1.4", "latest"}); err != nil {
+		t.Fatalf("tagLayout() error = %v", err)
+	}
+
+	got, err := readLayoutTagDigests(ref, []string{"1.4", "latest", "missing"})
+	if err != nil {
+		t.Fatalf("readLayoutTagDigests() error = %v", err)
+	}
+	want := map[string]string{"1.4": digest.String(), "latest": digest.String()}
+	if !maps.Equal(got, want) {
+		t.Errorf("readLayoutTagDigests() = %v, want %v", got, want)
+	}
+
+	tags, err := layoutTagsReferencingDigest(ref, digest.String(), nil)
+	if err != nil {
+		t.Fatalf("layoutTagsReferencingDigest() error = %v", err)
+	}
+	slices.Sort(tags)
+	if !slices.Equal(tags, []string{"1.4", "latest"}) {
+		t.Errorf("layoutTagsReferencingDigest() = %v, want [1.4 latest]", tags)
+	}
+
+	// The additional tags managed along with ref are not counted as other tags.
+	if tags, err := layoutTagsReferencingDigest(ref, digest.String(), []string{ref.tagged("1.4"), ref.tagged("latest")}); err != nil || len(tags) != 0 {
+		t.Errorf("layoutTagsReferencingDigest(managed) = %v, %v, want none", tags, err)
+	}
+
+	if err := removeLayoutDestination(t.Context(), ref, digest.String(), deleteModeTag, []string{"1.4"}); err != nil {
+		t.Fatalf("removeLayoutDestination(tag) error = %v", err)
+	}
+	if _, err := layoutDigest(ref.tagged(ref.tag)); !errors.Is(err, errLayoutNotFound) {
+		t.Errorf("layoutDigest() after removing the tag error = %v, want %v", err, errLayoutNotFound)
+	}
+	if got, err := readLayoutTagDigests(ref, []string{"1.4", "latest"}); err != nil || !maps.Equal(got, map[string]string{"latest": digest.String()}) {
+		t.Errorf("readLayoutTagDigests() after removing the tags = %v, %v, want only latest", got, err)
+	}
+	if err := removeLayoutDestination(t.Context(), ref, digest.String(), deleteModeManifest, nil); err != nil {
+		t.Fatalf("removeLayoutDestination(manifest) error = %v", err)
+	}
+	if got, err := readLayoutTagDigests(ref, []string{"1.4", "latest"}); err != nil || len(got) != 0 {
+		t.Errorf("readLayoutTagDigests() after removing the manifest = %v, %v, want none", got, err)
+	}
+}
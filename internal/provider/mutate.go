@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// mutateModel describes the changes made to an image before it is pushed.
+type mutateModel struct {
+	Labels       types.Map    `tfsdk:"labels"`
+	Annotations  types.Map    `tfsdk:"annotations"`
+	Env          types.Map    `tfsdk:"env"`
+	Entrypoint   types.List   `tfsdk:"entrypoint"`
+	Cmd          types.List   `tfsdk:"cmd"`
+	WorkingDir   types.String `tfsdk:"working_dir"`
+	User         types.String `tfsdk:"user"`
+	ExposedPorts types.Set    `tfsdk:"exposed_ports"`
+}
+
+// imageMutation holds the changes made to an image. Nil fields leave the image unchanged,
+// while an empty entrypoint or cmd clears it.
+type imageMutation struct {
+	labels       map[string]string
+	annotations  map[string]string
+	env          map[string]string
+	entrypoint   []string
+	cmd          []string
+	workingDir   *string
+	user         *string
+	exposedPorts []string
+}
+
+// mutation converts m to the changes to make to an image.
+func (m *mutateModel) mutation(ctx context.Context) (imageMutation, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var mutation imageMutation
+
+	if !m.Labels.IsNull() {
+		diags.Append(m.Labels.ElementsAs(ctx, &mutation.labels, false)...)
+	}
+	if !m.Annotations.IsNull() {
+		diags.Append(m.Annotations.ElementsAs(ctx, &mutation.annotations, false)...)
+	}
+	if !m.Env.IsNull() {
+		diags.Append(m.Env.ElementsAs(ctx, &mutation.env, false)...)
+	}
+	if !m.Entrypoint.IsNull() {
+		mutation.entrypoint = []string{}
+		diags.Append(m.Entrypoint.ElementsAs(ctx, &mutation.entrypoint, false)...)
+	}
+	if !m.Cmd.IsNull() {
+		mutation.cmd = []string{}
+		diags.Append(m.Cmd.ElementsAs(ctx, &mutation.cmd, false)...)
+	}
+	if !m.WorkingDir.IsNull() {
+		mutation.workingDir = m.WorkingDir.ValueStringPointer()
+	}
+	if !m.User.IsNull() {
+		mutation.user = m.User.ValueStringPointer()
+	}
+	if !m.ExposedPorts.IsNull() {
+		diags.Append(m.ExposedPorts.ElementsAs(ctx, &mutation.exposedPorts, false)...)
+	}
+	return mutation, diags
+}
+
+// mutateImage applies mutation to img, which is an image or an index, and returns the
+// result along with its digest. Every image of an index is mutated. Attestations describe
+// the original images, so they are dropped from the index.
+func mutateImage(img remote.Taggable, mutation imageMutation) (remote.Taggable, string, error) {
+	if desc, ok := img.(*remote.Descriptor); ok {
+		if desc.MediaType.IsIndex() {
+			idx, err := desc.ImageIndex()
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read image index: %w", err)
+			}
+			img = idx
+		} else {
+			image, err := desc.Image()
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read image: %w", err)
+			}
+			img = image
+		}
+	}
+
+	switch img := img.(type) {
+	case v1.ImageIndex:
+		mutated, err := mutateIndex(img, mutation)
+		if err != nil {
+			return nil, "", err
+		}
+		digest, err := mutated.Digest()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get digest of mutated image: %w", err)
+		}
+		return mutated, digest.String(), nil
+	case v1.Image:
+		mutated, err := mutateSingleImage(img, mutation)
+		if err != nil {
+			return nil, "", err
+		}
+		digest, err := mutated.Digest()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get digest of mutated image: %w", err)
+		}
+		return mutated, digest.String(), nil
+	default:
+		return nil, "", fmt.Errorf("unsupported image type %T", img)
+	}
+}
+
+func mutateIndex(idx v1.ImageIndex, mutation imageMutation) (v1.ImageIndex, error) {
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index manifest: %w", err)
+	}
+
+	// The index is rebuilt from its own manifests so that its media type and annotations
+	// are kept.
+	mutated := mutate.RemoveManifests(idx, func(v1.Descriptor) bool { return true })
+	for _, child := range manifest.Manifests {
+		descriptor := v1.Descriptor{
+			Platform:    child.Platform,
+			Annotations: child.Annotations,
+		}
+		switch {
+		case child.Platform != nil && (child.Platform.OS == "unknown" || child.Platform.Architecture == "unknown"):
+			continue
+		case child.MediaType.IsImage():
+			img, err := idx.Image(child.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read image %s: %w", child.Digest, err)
+			}
+			img, err = mutateSingleImage(img, mutation)
+			if err != nil {
+				return nil, err
+			}
+			mutated = mutate.AppendManifests(mutated, mutate.IndexAddendum{Add: img, Descriptor: descriptor})
+		case child.MediaType.IsIndex():
+			nested, err := idx.ImageIndex(child.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read image index %s: %w", child.Digest, err)
+			}
+			nested, err = mutateIndex(nested, mutation)
+			if err != nil {
+				return nil, err
+			}
+			mutated = mutate.AppendManifests(mutated, mutate.IndexAddendum{Add: nested, Descriptor: descriptor})
+		default:
+			return nil, fmt.Errorf("unsupported manifest media type %q in index", child.MediaType)
+		}
+	}
+
+	if len(mutation.annotations) > 0 {
+		annotated, ok := mutate.Annotations(mutated, mutation.annotations).(v1.ImageIndex)
+		if !ok {
+			return nil, fmt.Errorf("failed to annotate image index")
+		}
+		mutated = annotated
+	}
+	return mutated, nil
+}
+
+func mutateSingleImage(img v1.Image, mutation imageMutation) (v1.Image, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config: %w", err)
+	}
+	config := *cfg.Config.DeepCopy()
+
+	if len(mutation.labels) > 0 {
+		labels := make(map[string]string, len(config.Labels)+len(mutation.labels))
+		for k, v := range config.Labels {
+			labels[k] = v
+		}
+		for k, v := range mutation.labels {
+			labels[k] = v
+		}
+		config.Labels = labels
+	}
+	if len(mutation.env) > 0 {
+		config.Env = mergeEnv(config.Env, mutation.env)
+	}
+	if mutation.entrypoint != nil {
+		config.Entrypoint = mutation.entrypoint
+	}
+	if mutation.cmd != nil {
+		config.Cmd = mutation.cmd
+	}
+	if mutation.workingDir != nil {
+		config.WorkingDir = *mutation.workingDir
+	}
+	if mutation.user != nil {
+		config.User = *mutation.user
+	}
+	if len(mutation.exposedPorts) > 0 {
+		ports := make(map[string]struct{}, len(config.ExposedPorts)+len(mutation.exposedPorts))
+		for port := range config.ExposedPorts {
+			ports[port] = struct{}{}
+		}
+		for _, port := range mutation.exposedPorts {
+			if !strings.Contains(port, "/") {
+				port += "/tcp"
+			}
+			ports[port] = struct{}{}
+		}
+		config.ExposedPorts = ports
+	}
+
+	img, err = mutate.Config(img, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set image config: %w", err)
+	}
+	if len(mutation.annotations) > 0 {
+		annotated, ok := mutate.Annotations(img, mutation.annotations).(v1.Image)
+		if !ok {
+			return nil, fmt.Errorf("failed to annotate image")
+		}
+		img = annotated
+	}
+	return img, nil
+}
+
+// mergeEnv sets the variables of overrides in env, which holds `NAME=value` entries.
+// Existing variables keep their position, and new ones are appended in name order so that
+// the resulting config is deterministic.
+func mergeEnv(env []string, overrides map[string]string) []string {
+	merged := make([]string, 0, len(env)+len(overrides))
+	set := map[string]bool{}
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if value, ok := overrides[name]; ok {
+			entry = name + "=" + value
+			set[name] = true
+		}
+		merged = append(merged, entry)
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		if !set[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		merged = append(merged, name+"="+overrides[name])
+	}
+	return merged
+}
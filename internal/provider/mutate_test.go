@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestMutateImageIndex(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// An index with two platforms and an attestation for the first one.
+	idx := mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	for _, platform := range []v1.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}, {OS: "unknown", Architecture: "unknown"}} {
+		img, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatalf("failed to create image: %v", err)
+		}
+		img, err = mutate.Config(img, v1.Config{Env: []string{"PATH=/bin", "HOME=/root"}, Labels: map[string]string{"vendor": "upstream"}})
+		if err != nil {
+			t.Fatalf("failed to set config: %v", err)
+		}
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &platform}})
+	}
+	source := host + "/vendor/app:latest"
+	sourceRef, err := name.ParseReference(source)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	if err := remote.WriteIndex(sourceRef, idx); err != nil {
+		t.Fatalf("failed to seed index: %v", err)
+	}
+
+	user := "nobody"
+	mutation := imageMutation{
+		labels:       map[string]string{"org.opencontainers.image.source": "https://example.com/app"},
+		annotations:  map[string]string{"owner": "platform"},
+		env:          map[string]string{"PATH": "/usr/bin", "TZ": "UTC"},
+		user:         &user,
+		exposedPorts: []string{"8080", "53/udp"},
+	}
+
+	loaded, sourceDigest, err := loadSource(source, nil, "")
+	if err != nil {
+		t.Fatalf("loadSource() error = %v", err)
+	}
+	mutated, digest, err := mutateImage(loaded, mutation)
+	if err != nil {
+		t.Fatalf("mutateImage() error = %v", err)
+	}
+	if digest == sourceDigest {
+		t.Errorf("mutateImage() digest = %s, want it to differ from the source", digest)
+	}
+	_, again, err := mutateImage(loaded, mutation)
+	if err != nil {
+		t.Fatalf("mutateImage() error = %v", err)
+	}
+	if again != digest {
+		t.Errorf("mutateImage() digest = %s, then %s, want it to be deterministic", digest, again)
+	}
+
+	destination := host + "/mirror/app:latest"
+	if _, d := pushDestination(t.Context(), mutated, source, digest, destination, nil, onConflictFail); d != nil {
+		t.Fatalf("pushDestination() error = %s", d.Detail())
+	}
+	if status, d := pushDestination(t.Context(), mutated, source, digest, destination, nil, onConflictFail); d != nil || status != destinationStatusUnchanged {
+		t.Errorf("pushDestination() of the mutated image again = %s, %v, want %s", status, d, destinationStatusUnchanged)
+	}
+
+	destinationRef, err := name.ParseReference(destination)
+	if err != nil {
+		t.Fatalf("failed to parse destination: %v", err)
+	}
+	pushed, err := remote.Index(destinationRef)
+	if err != nil {
+		t.Fatalf("failed to read pushed index: %v", err)
+	}
+	manifest, err := pushed.IndexManifest()
+	if err != nil {
+		t.Fatalf("failed to read pushed index manifest: %v", err)
+	}
+	if len(manifest.Manifests) != 2 {
+		t.Errorf("pushed index has %d manifests, want 2 without the attestation", len(manifest.Manifests))
+	}
+	if manifest.Annotations["owner"] != "platform" {
+		t.Errorf("pushed index annotations = %v, want owner annotation", manifest.Annotations)
+	}
+
+	cfg, err := crane.Config(destination, crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"}))
+	if err != nil {
+		t.Fatalf("failed to read pushed config: %v", err)
+	}
+	config, err := v1.ParseConfigFile(strings.NewReader(string(cfg)))
+	if err != nil {
+		t.Fatalf("failed to parse pushed config: %v", err)
+	}
+	if config.Config.Labels["vendor"] != "upstream" || config.Config.Labels["org.opencontainers.image.source"] != "https://example.com/app" {
+		t.Errorf("pushed labels = %v, want source and added labels", config.Config.Labels)
+	}
+	if want := []string{"PATH=/usr/bin", "HOME=/root", "TZ=UTC"}; !slices.Equal(config.Config.Env, want) {
+		t.Errorf("pushed env = %v, want %v", config.Config.Env, want)
+	}
+	if config.Config.User != "nobody" {
+		t.Errorf("pushed user = %q, want nobody", config.Config.User)
+	}
+	if _, ok := config.Config.ExposedPorts["8080/tcp"]; !ok {
+		t.Errorf("pushed exposed ports = %v, want 8080/tcp", config.Config.ExposedPorts)
+	}
+	if _, ok := config.Config.ExposedPorts["53/udp"]; !ok {
+		t.Errorf("pushed exposed ports = %v, want 53/udp", config.Config.ExposedPorts)
+	}
+}
+
+func TestMutateImageEntrypoint(t *testing.T) {
+	img, err := mutate.Config(empty.Image, v1.Config{Entrypoint: []string{"/bin/sh"}, Cmd: []string{"-c", "true"}})
+	if err != nil {
+		t.Fatalf("failed to set config: %v", err)
+	}
+
+	mutated, _, err := mutateImage(img, imageMutation{entrypoint: []string{"/app"}, cmd: []string{}})
+	if err != nil {
+		t.Fatalf("mutateImage() error = %v", err)
+	}
+	mutatedImage, ok := mutated.(v1.Image)
+	if !ok {
+		t.Fatalf("mutateImage() = %T, want v1.Image", mutated)
+	}
+	config, err := mutatedImage.ConfigFile()
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if !slices.Equal(config.Config.Entrypoint, []string{"/app"}) {
+		t.Errorf("entrypoint = %v, want [/app]", config.Config.Entrypoint)
+	}
+	if len(config.Config.Cmd) != 0 {
+		t.Errorf("cmd = %v, want it cleared", config.Config.Cmd)
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	got := mergeEnv([]string{"PATH=/bin", "EMPTY", "HOME=/root"}, map[string]string{"HOME": "/home/app", "B": "2", "A": "1=1"})
+	want := []string{"PATH=/bin", "EMPTY", "HOME=/home/app", "A=1=1", "B=2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mergeEnv() = %v, want %v", got, want)
+	}
+}
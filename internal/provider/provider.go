@@ -7,8 +7,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -27,7 +29,14 @@ type CraneProvider struct {
 }
 
 type craneProviderModel struct {
-	AllowNondistributableArtifacts types.Bool `tfsdk:"allow_nondistributable_artifacts"`
+	AllowNondistributableArtifacts types.Bool          `tfsdk:"allow_nondistributable_artifacts"`
+	RegistryAuth                   []registryAuthModel `tfsdk:"registry_auth"`
+	InsecureRegistries             types.List          `tfsdk:"insecure_registries"`
+	CACertificate                  types.String        `tfsdk:"ca_certificate"`
+	ClientCertificate              types.String        `tfsdk:"client_certificate"`
+	ClientKey                      types.String        `tfsdk:"client_key"`
+	Mirror                         []mirrorModel       `tfsdk:"mirror"`
+	Retry                          *retryModel         `tfsdk:"retry"`
 }
 
 func (p *CraneProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -42,6 +51,108 @@ func (p *CraneProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 				Optional:            true,
 				MarkdownDescription: "Allow pushing non-distributable (foreign) layers",
 			},
+			"insecure_registries": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Registry hosts that may be reached over plain HTTP or with unverified TLS certificates (e.g. `registry.local:5000`).",
+			},
+			"ca_certificate": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded CA certificates, or the path to a file containing them, trusted in addition to the system roots.",
+			},
+			"client_certificate": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded client certificate, or the path to a file containing it, presented to registries requiring mutual TLS. Requires `client_key`.",
+			},
+			"client_key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "PEM encoded private key, or the path to a file containing it, for `client_certificate`.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"mirror": schema.ListNestedBlock{
+				MarkdownDescription: "Mirrors to read images from instead of an upstream registry. Mirrors are tried in order and the upstream registry is used when no mirror has the image. Mirrors apply to every image that is only read: `crane_image` sources, the `crane_appended_image` base, the images of `crane_image_index`, and the references of the `crane_digest`, `crane_image_manifest`, `crane_image_config` and `crane_image_platforms` data sources. They never apply to push destinations.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"registry": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "The upstream registry host to mirror (e.g. `docker.io`).",
+						},
+						"endpoints": schema.ListAttribute{
+							Required:            true,
+							ElementType:         types.StringType,
+							MarkdownDescription: "Mirror hosts, optionally with a path prefix, to try in order (e.g. `mirror.example.com/docker-hub`).",
+						},
+					},
+				},
+			},
+			"retry": schema.SingleNestedBlock{
+				MarkdownDescription: "Retry policy applied to every registry request made by the provider. Transport errors and the configured status codes are retried with exponential backoff, honoring the `Retry-After` response header. Temporary network errors, such as refused or reset connections, are additionally retried by the underlying registry client, so they may be attempted up to three times `max_attempts` times.",
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: "The maximum number of attempts for a request, including the first. (default 5)",
+					},
+					"initial_backoff": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "The delay before the first retry, doubled for each subsequent retry (e.g. `500ms`). (default `1s`)",
+					},
+					"max_backoff": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "The maximum delay between retries. Responses asking to wait longer via `Retry-After` are not retried. (default `30s`)",
+					},
+					"retryable_status_codes": schema.ListAttribute{
+						Optional:            true,
+						ElementType:         types.Int64Type,
+						MarkdownDescription: "HTTP status codes to retry. (default `[408, 429, 500, 502, 503, 504]`)",
+					},
+				},
+			},
+			"registry_auth": schema.ListNestedBlock{
+				MarkdownDescription: "Credentials for a registry. Configured credentials take precedence over the default Docker keychain.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"address": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "The registry host the credentials apply to (e.g. `ghcr.io` or `registry.example.com:5000`).",
+						},
+						"username": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "The username to authenticate with.",
+						},
+						"password": schema.StringAttribute{
+							Optional:            true,
+							Sensitive:           true,
+							MarkdownDescription: "The password or access token to authenticate with. Requires `username`.",
+						},
+						"identity_token": schema.StringAttribute{
+							Optional:            true,
+							Sensitive:           true,
+							MarkdownDescription: "An identity (refresh) token exchanged with the registry for an access token. Conflicts with `password`.",
+						},
+					},
+					Blocks: map[string]schema.Block{
+						"ecr": schema.SingleNestedBlock{
+							MarkdownDescription: "Authenticate to Amazon ECR with an authorization token obtained from the AWS credential chain. The token is refreshed automatically before it expires. Conflicts with `username`, `password` and `identity_token`.",
+							Attributes: map[string]schema.Attribute{
+								"region": schema.StringAttribute{
+									Optional:            true,
+									MarkdownDescription: "The AWS region of the registry. Defaults to the region in `address`.",
+								},
+								"profile": schema.StringAttribute{
+									Optional:            true,
+									MarkdownDescription: "The AWS shared configuration profile to load credentials from.",
+								},
+								"assume_role_arn": schema.StringAttribute{
+									Optional:            true,
+									MarkdownDescription: "The ARN of an IAM role to assume before requesting the authorization token.",
+								},
+							},
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -58,15 +169,64 @@ func (p *CraneProvider) Configure(ctx context.Context, req provider.ConfigureReq
 	if !config.AllowNondistributableArtifacts.IsNull() && config.AllowNondistributableArtifacts.ValueBool() {
 		craneOpts = append(craneOpts, crane.WithNondistributable())
 	}
+	if len(config.RegistryAuth) > 0 {
+		keychain, err := newRegistryKeychain(config.RegistryAuth)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("registry_auth"), "Invalid registry credentials", err.Error())
+			return
+		}
+		craneOpts = append(craneOpts, crane.WithAuthFromKeychain(authn.NewMultiKeychain(keychain, authn.DefaultKeychain)))
+	}
+	insecureRegistries, err := newInsecureRegistries(ctx, config.InsecureRegistries)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("insecure_registries"), "Invalid insecure registry", err.Error())
+		return
+	}
+	var retry *retryPolicy
+	if config.Retry != nil {
+		retry, err = newRetryPolicy(ctx, *config.Retry)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("retry"), "Invalid retry policy", err.Error())
+			return
+		}
+		craneOpts = append(craneOpts, disableBuiltinStatusRetries)
+	}
+	transport, err := newTransport(transportConfig{
+		retry:              retry,
+		insecureRegistries: insecureRegistries,
+		caCertificate:      config.CACertificate.ValueString(),
+		clientCertificate:  config.ClientCertificate.ValueString(),
+		clientKey:          config.ClientKey.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid TLS configuration", err.Error())
+		return
+	}
+	if transport != nil {
+		craneOpts = append(craneOpts, crane.WithTransport(transport))
+	}
 	craneOpts = append(craneOpts, crane.WithUserAgent(fmt.Sprintf("terraform-provider-crane/%s", p.version)))
 
-	resp.ResourceData = craneOpts
-	resp.DataSourceData = craneOpts
+	mirrors, err := newMirrors(ctx, config.Mirror)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("mirror"), "Invalid registry mirror", err.Error())
+		return
+	}
+
+	client := &registryClient{
+		options: craneOpts,
+		mirrors: mirrors,
+	}
+	resp.ResourceData = client
+	resp.DataSourceData = client
 }
 
 func (p *CraneProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewImageResource,
+		NewTagResource,
+		NewAppendedImageResource,
+		NewImageIndexResource,
 	}
 }
 
@@ -74,6 +234,9 @@ func (p *CraneProvider) DataSources(ctx context.Context) []func() datasource.Dat
 	return []func() datasource.DataSource{
 		NewTagsDataSource,
 		NewDigestDataSource,
+		NewImageManifestDataSource,
+		NewImageConfigDataSource,
+		NewImagePlatformsDataSource,
 	}
 }
 
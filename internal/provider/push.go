@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	destinationStatusPushed    = "pushed"
+	destinationStatusUnchanged = "unchanged"
+	destinationStatusFailed    = "failed"
+	destinationStatusSkipped   = "skipped"
+)
+
+const (
+	onConflictFail      = "fail"
+	onConflictOverwrite = "overwrite"
+	onConflictSkip      = "skip"
+	onConflictBackup    = "backup"
+)
+
+// destinationResultModel describes the outcome of pushing an image to one of its destinations.
+type destinationResultModel struct {
+	Status          types.String `tfsdk:"status"`
+	Digest          types.String `tfsdk:"digest"`
+	PinnedReference types.String `tfsdk:"pinned_reference"`
+	Error           types.String `tfsdk:"error"`
+}
+
+var destinationResultAttrTypes = map[string]attr.Type{
+	"status":           types.StringType,
+	"digest":           types.StringType,
+	"pinned_reference": types.StringType,
+	"error":            types.StringType,
+}
+
+// loadSource reads source once so that it can be pushed to any number of destinations, and
+// returns it along with its digest. When cacheDir is set, remote layers are cached there so
+// that each blob is only fetched from the source registry once.
+func loadSource(source string, opts []crane.Option, cacheDir string) (remote.Taggable, string, error) {
+	// Image is in an OCI image layout directory
+	if isOCILayout(source) {
+		return loadLayout(source, crane.GetOptions(opts...).Platform)
+	}
+
+	// Image is a tarball
+	if _, err := os.Stat(source); err == nil {
+		img, err := crane.Load(source, opts...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load image from tarball: %w", err)
+		}
+		hash, err := img.Digest()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get digest of image: %w", err)
+		}
+		return img, hash.String(), nil
+	}
+
+	// Image is a remote image reference
+	o := crane.GetOptions(opts...)
+	ref, err := name.ParseReference(source, o.Name...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse remote image reference: %w", err)
+	}
+	desc, err := remote.Get(ref, o.Remote...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read remote image: %w", err)
+	}
+
+	switch {
+	case o.Platform == nil && desc.MediaType.IsIndex():
+		if cacheDir == "" {
+			return desc, desc.Digest.String(), nil
+		}
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read image index: %w", err)
+		}
+		return cache.ImageIndex(idx, cache.NewFilesystemCache(cacheDir)), desc.Digest.String(), nil
+	case o.Platform == nil && (cacheDir == "" || !desc.MediaType.IsImage()):
+		return desc, desc.Digest.String(), nil
+	}
+
+	// If platform is explicitly set, only the matching image is copied rather than the index.
+	img, err := desc.Image()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	hash, err := img.Digest()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get digest of image: %w", err)
+	}
+	if cacheDir != "" {
+		img = cache.Image(img, cache.NewFilesystemCache(cacheDir))
+	}
+	return img, hash.String(), nil
+}
+
+// pushDestination pushes the image loaded from source to destination and returns the
+// resulting destination status. Unless onConflict is `overwrite`, a destination already
+// holding digest is left untouched, and a destination holding a different image is handled
+// according to onConflict: `fail` reports a conflict, `skip` leaves it in place and `backup`
+// tags it as `<tag>-prev-<timestamp>` before it is overwritten.
+func pushDestination(ctx context.Context, img remote.Taggable, source string, digest string, destination string, opts []crane.Option, onConflict string) (string, diag.Diagnostic) {
+	if isOCILayout(destination) {
+		return pushLayoutDestination(ctx, img, source, digest, destination, onConflict)
+	}
+	o := crane.GetOptions(opts...)
+
+	destRef, err := name.ParseReference(destination, o.Name...)
+	if err != nil {
+		return destinationStatusFailed, diag.NewErrorDiagnostic(
+			"Error parsing destination image reference",
+			fmt.Sprintf("Unable to parse destination image reference '%s': %s", destination, err),
+		)
+	}
+
+	if onConflict != onConflictOverwrite {
+		// Check if the image already exists at the destination
+		existing, err := remote.Get(destRef, o.Remote...)
+		if err != nil {
+			var remoteErr *transport.Error
+			if ok := errors.As(err, &remoteErr); ok && remoteErr.StatusCode != http.StatusNotFound {
+				return destinationStatusFailed, diag.NewErrorDiagnostic(
+					"Error checking destination repository",
+					fmt.Sprintf("Error checking destination repository '%s': %s", destination, err),
+				)
+			}
+		} else if existing.Digest.String() == digest {
+			tflog.Debug(ctx, fmt.Sprintf("Destination image '%s' already exists, skipping push", destination))
+			return destinationStatusUnchanged, nil
+		} else {
+			switch onConflict {
+			case onConflictSkip:
+				tflog.Debug(ctx, fmt.Sprintf("Destination image '%s' already exists with digest '%s', skipping push", destination, existing.Digest))
+				return destinationStatusSkipped, nil
+			case onConflictBackup:
+				if d := backupDestination(ctx, destRef, existing, o); d != nil {
+					return destinationStatusFailed, d
+				}
+			default:
+				return destinationStatusFailed, diag.NewErrorDiagnostic(
+					"Destination image already exists but does not match source",
+					fmt.Sprintf("Destination image '%s' already exists with a different digest.", destination),
+				)
+			}
+		}
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Pushing '%s' to '%s'", source, destination))
+	if err := remote.Push(destRef, img, o.Remote...); err != nil {
+		return destinationStatusFailed, diag.NewErrorDiagnostic(
+			"Error pushing image to destination",
+			fmt.Sprintf("Unable to push image '%s' to destination '%s': failed to push image to destination: %s", source, destination, err),
+		)
+	}
+	return destinationStatusPushed, nil
+}
+
+// backupDestination tags the existing image found at ref as `<tag>-prev-<timestamp>` so that
+// it can still be pulled once ref is overwritten.
+func backupDestination(ctx context.Context, ref name.Reference, existing *remote.Descriptor, o crane.Options) diag.Diagnostic {
+	tag, ok := ref.(name.Tag)
+	if !ok {
+		return diag.NewErrorDiagnostic(
+			"Error backing up destination image",
+			fmt.Sprintf("Destination '%s' is a digest reference, so there is no tag to back up.", ref),
+		)
+	}
+
+	backup := tag.Context().Tag(fmt.Sprintf("%s-prev-%s", tag.TagStr(), time.Now().UTC().Format("20060102150405")))
+	tflog.Debug(ctx, fmt.Sprintf("Backing up '%s' as '%s'", ref, backup))
+	if err := remote.Tag(backup, existing, o.Remote...); err != nil {
+		return diag.NewErrorDiagnostic(
+			"Error backing up destination image",
+			fmt.Sprintf("Unable to tag the image found at '%s' as '%s' before overwriting it: %s", ref, backup, err),
+		)
+	}
+	return nil
+}
+
+// tagImage points each of tags in the repository of ref at the image ref is pinned to. The
+// image manifest is uploaded under each tag, so no layers are copied.
+func tagImage(ctx context.Context, ref name.Digest, tags []string, o crane.Options) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	desc, err := remote.Get(ref, o.Remote...)
+	if err != nil {
+		return fmt.Errorf("failed to read image: %w", err)
+	}
+	for _, tag := range tags {
+		dst := ref.Context().Tag(tag)
+		tflog.Debug(ctx, fmt.Sprintf("Tagging '%s' as '%s'", ref, dst))
+		if err := remote.Tag(dst, desc, o.Remote...); err != nil {
+			return fmt.Errorf("failed to tag image as '%s': %w", dst, err)
+		}
+	}
+	return nil
+}
+
+// readTagDigests returns the digest each of tags in repo points at. Tags that do not exist
+// are omitted.
+func readTagDigests(ctx context.Context, repo name.Repository, tags []string, o crane.Options) (map[string]string, error) {
+	digests := map[string]string{}
+	for _, tag := range tags {
+		desc, err := remote.Head(repo.Tag(tag), o.Remote...)
+		if err != nil {
+			var remoteErr *transport.Error
+			if errors.As(err, &remoteErr) && remoteErr.StatusCode == http.StatusNotFound {
+				tflog.Debug(ctx, fmt.Sprintf("Tag '%s' no longer exists", repo.Tag(tag)))
+				continue
+			}
+			return nil, fmt.Errorf("failed to read tag '%s': %w", repo.Tag(tag), err)
+		}
+		digests[tag] = desc.Digest.String()
+	}
+	return digests, nil
+}
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestLoadSourceFetchesBlobsOnce(t *testing.T) {
+	var blobGets atomic.Int32
+	reg := testRegistry()
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") {
+			blobGets.Add(1)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer source.Close()
+	destination := httptest.NewServer(testRegistry())
+	defer destination.Close()
+
+	img, err := random.Image(64, 3)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	sourceRef := strings.TrimPrefix(source.URL, "http://") + "/app:latest"
+	if err := crane.Push(img, sourceRef); err != nil {
+		t.Fatalf("failed to seed image: %v", err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+
+	loaded, digest, err := loadSource(sourceRef, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("loadSource() error = %v", err)
+	}
+	if digest != want.String() {
+		t.Errorf("loadSource() digest = %s, want %s", digest, want)
+	}
+
+	for i := range 3 {
+		dst := fmt.Sprintf("%s/app-%d:latest", strings.TrimPrefix(destination.URL, "http://"), i)
+		status, d := pushDestination(t.Context(), loaded, sourceRef, digest, dst, nil, onConflictFail)
+		if d != nil {
+			t.Fatalf("pushDestination(%s) error = %s", dst, d.Detail())
+		}
+		if status != destinationStatusPushed {
+			t.Errorf("pushDestination(%s) status = %s, want %s", dst, status, destinationStatusPushed)
+		}
+	}
+
+	// Three layers and the config blob.
+	if got := blobGets.Load(); got != 4 {
+		t.Errorf("source blobs fetched %d times, want 4", got)
+	}
+}
+
+func TestPushDestinationExisting(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	other, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	if err := crane.Push(img, registry+"/same:latest"); err != nil {
+		t.Fatalf("failed to seed image: %v", err)
+	}
+	if err := crane.Push(other, registry+"/different:latest"); err != nil {
+		t.Fatalf("failed to seed image: %v", err)
+	}
+
+	status, d := pushDestination(t.Context(), img, "source", digest.String(), registry+"/same:latest", nil, onConflictFail)
+	if d != nil || status != destinationStatusUnchanged {
+		t.Errorf("pushDestination(same) = %s, %v, want %s", status, d, destinationStatusUnchanged)
+	}
+
+	status, d = pushDestination(t.Context(), img, "source", digest.String(), registry+"/different:latest", nil, onConflictFail)
+	if d == nil || status != destinationStatusFailed {
+		t.Fatalf("pushDestination(different) = %s, %v, want a conflict", status, d)
+	}
+	if d.Summary() != "Destination image already exists but does not match source" {
+		t.Errorf("pushDestination(different) summary = %q", d.Summary())
+	}
+
+	otherDigest, err := other.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	status, d = pushDestination(t.Context(), img, "source", digest.String(), registry+"/different:latest", nil, onConflictSkip)
+	if d != nil || status != destinationStatusSkipped {
+		t.Errorf("pushDestination(different, skip) = %s, %v, want %s", status, d, destinationStatusSkipped)
+	}
+	if got, err := crane.Digest(registry + "/different:latest"); err != nil || got != otherDigest.String() {
+		t.Errorf("destination after skip = %s, %v, want %s", got, err, otherDigest)
+	}
+
+	status, d = pushDestination(t.Context(), img, "source", digest.String(), registry+"/different:latest", nil, onConflictBackup)
+	if d != nil || status != destinationStatusPushed {
+		t.Fatalf("pushDestination(different, backup) = %s, %v, want %s", status, d, destinationStatusPushed)
+	}
+	tags, err := crane.ListTags(registry + "/different")
+	if err != nil {
+		t.Fatalf("failed to list tags: %v", err)
+	}
+	var backups []string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "latest-prev-") {
+			backups = append(backups, tag)
+		}
+	}
+	if len(backups) != 1 {
+		t.Fatalf("backup tags = %v, want one latest-prev-<timestamp> tag", tags)
+	}
+	if got, err := crane.Digest(registry + "/different:" + backups[0]); err != nil || got != otherDigest.String() {
+		t.Errorf("backup %s = %s, %v, want %s", backups[0], got, err, otherDigest)
+	}
+	if got, err := crane.Digest(registry + "/different:latest"); err != nil || got != digest.String() {
+		t.Errorf("destination after backup = %s, %v, want %s", got, err, digest)
+	}
+
+	status, d = pushDestination(t.Context(), other, "source", otherDigest.String(), registry+"/different:latest", nil, onConflictOverwrite)
+	if d != nil || status != destinationStatusPushed {
+		t.Errorf("pushDestination(different, overwrite) = %s, %v, want %s", status, d, destinationStatusPushed)
+	}
+}
+
+func TestTagImage(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	repo, err := name.NewRepository(strings.TrimPrefix(server.URL, "http://") + "/app")
+	if err != nil {
+		t.Fatalf("failed to parse repository: %v", err)
+	}
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	if err := remote.Write(repo.Tag("1.4.2"), img); err != nil {
+		t.Fatalf("failed to seed image: %v", err)
+	}
+
+	o := crane.GetOptions()
+	if err := tagImage(t.Context(), repo.Digest(digest.String()), []string{"1.4", "latest"}, o); err != nil {
+		t.Fatalf("tagImage() error = %v", err)
+	}
+
+	got, err := readTagDigests(t.Context(), repo, []string{"1.4", "latest", "missing"}, o)
+	if err != nil {
+		t.Fatalf("readTagDigests() error = %v", err)
+	}
+	want := map[string]string{"1.4": digest.String(), "latest": digest.String()}
+	if !maps.Equal(got, want) {
+		t.Errorf("readTagDigests() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// cosignTagSuffixes are the suffixes of the tags cosign stores signatures, attestations and
+// SBOMs under, next to the image they describe.
+var cosignTagSuffixes = []string{".sig", ".att", ".sbom"}
+
+// cosignTag returns the tag cosign stores the artifacts of digest with suffix under in repo.
+func cosignTag(repo name.Repository, digest v1.Hash, suffix string) name.Tag {
+	return repo.Tag(fmt.Sprintf("%s-%s%s", digest.Algorithm, digest.Hex, suffix))
+}
+
+// copyReferrers copies the artifacts referring to digest from the src repository to dst, and
+// returns their digests in sorted order. Referrers are discovered through the referrers API,
+// falling back to the referrers tag for registries without it, and through the cosign tag
+// scheme. Artifacts referring to those artifacts, such as signatures of attestations, are
+// copied as well.
+func copyReferrers(ctx context.Context, src name.Repository, dst name.Repository, digest string, o crane.Options) ([]string, error) {
+	subject, err := v1.NewHash(digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse digest '%s': %w", digest, err)
+	}
+
+	copied := map[string]bool{}
+	queue := []v1.Hash{subject}
+	for len(queue) > 0 {
+		subject, queue = queue[0], queue[1:]
+
+		referrers, err := remote.Referrers(src.Digest(subject.String()), o.Remote...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list referrers of '%s': %w", src.Digest(subject.String()), err)
+		}
+		manifest, err := referrers.IndexManifest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list referrers of '%s': %w", src.Digest(subject.String()), err)
+		}
+		for _, referrer := range manifest.Manifests {
+			if copied[referrer.Digest.String()] {
+				continue
+			}
+			if err := copyManifest(ctx, src.Digest(referrer.Digest.String()), dst.Digest(referrer.Digest.String()), o); err != nil {
+				return nil, err
+			}
+			copied[referrer.Digest.String()] = true
+			queue = append(queue, referrer.Digest)
+		}
+
+		for _, suffix := range cosignTagSuffixes {
+			tag := cosignTag(src, subject, suffix)
+			desc, err := remote.Head(tag, o.Remote...)
+			if err != nil {
+				var remoteErr *transport.Error
+				if errors.As(err, &remoteErr) && remoteErr.StatusCode == http.StatusNotFound {
+					continue
+				}
+				return nil, fmt.Errorf("failed to read '%s': %w", tag, err)
+			}
+			if err := copyManifest(ctx, tag, cosignTag(dst, subject, suffix), o); err != nil {
+				return nil, err
+			}
+			if !copied[desc.Digest.String()] {
+				copied[desc.Digest.String()] = true
+				queue = append(queue, desc.Digest)
+			}
+		}
+	}
+
+	digests := make([]string, 0, len(copied))
+	for digest := range copied {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+	return digests, nil
+}
+
+// copyManifest copies the manifest at src, along with the blobs it references, to dst.
+func copyManifest(ctx context.Context, src name.Reference, dst name.Reference, o crane.Options) error {
+	desc, err := remote.Get(src, o.Remote...)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s': %w", src, err)
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Copying referrer '%s' to '%s'", src, dst))
+	if err := remote.Push(dst, desc, o.Remote...); err != nil {
+		return fmt.Errorf("failed to copy '%s' to '%s': %w", src, dst, err)
+	}
+	return nil
+}
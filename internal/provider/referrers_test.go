@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// pushReferrer pushes a random artifact referring to subject in repo and returns its digest.
+func pushReferrer(t *testing.T, repo name.Repository, subject v1.Descriptor) string {
+	t.Helper()
+
+	artifact, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create artifact: %v", err)
+	}
+	artifact, ok := mutate.Subject(artifact, subject).(v1.Image)
+	if !ok {
+		t.Fatal("failed to set artifact subject")
+	}
+	digest, err := artifact.Digest()
+	if err != nil {
+		t.Fatalf("failed to read artifact digest: %v", err)
+	}
+	if err := remote.Write(repo.Digest(digest.String()), artifact); err != nil {
+		t.Fatalf("failed to push artifact: %v", err)
+	}
+	return digest.String()
+}
+
+func TestCopyReferrers(t *testing.T) {
+	// The source supports the referrers API while the destination only supports the
+	// fallback tag.
+	src := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.WithReferrersSupport(true)))
+	defer src.Close()
+	dst := httptest.NewServer(testRegistry())
+	defer dst.Close()
+	srcRepo, err := name.NewRepository(strings.TrimPrefix(src.URL, "http://") + "/app")
+	if err != nil {
+		t.Fatalf("failed to parse repository: %v", err)
+	}
+	dstRepo, err := name.NewRepository(strings.TrimPrefix(dst.URL, "http://") + "/app")
+	if err != nil {
+		t.Fatalf("failed to parse repository: %v", err)
+	}
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	if err := remote.Write(srcRepo.Tag("latest"), img); err != nil {
+		t.Fatalf("failed to push image: %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	mediaType, err := img.MediaType()
+	if err != nil {
+		t.Fatalf("failed to read media type: %v", err)
+	}
+	size, err := img.Size()
+	if err != nil {
+		t.Fatalf("failed to read size: %v", err)
+	}
+
+	// An SBOM referring to the image, signed in turn, and a cosign signature stored by tag.
+	sbom := pushReferrer(t, srcRepo, v1.Descriptor{MediaType: mediaType, Digest: digest, Size: size})
+	sbomDesc, err := remote.Head(srcRepo.Digest(sbom))
+	if err != nil {
+		t.Fatalf("failed to read sbom: %v", err)
+	}
+	sbomSignature := pushReferrer(t, srcRepo, *sbomDesc)
+	signature, err := mutate.Append(empty.Image, mutate.Addendum{Layer: static.NewLayer([]byte("signature"), types.OCIUncompressedLayer)})
+	if err != nil {
+		t.Fatalf("failed to create signature: %v", err)
+	}
+	if err := remote.Write(cosignTag(srcRepo, digest, ".sig"), signature); err != nil {
+		t.Fatalf("failed to push signature: %v", err)
+	}
+	signatureDigest, err := signature.Digest()
+	if err != nil {
+		t.Fatalf("failed to read signature digest: %v", err)
+	}
+
+	if err := crane.Copy(srcRepo.Tag("latest").String(), dstRepo.Tag("latest").String()); err != nil {
+		t.Fatalf("failed to copy image: %v", err)
+	}
+	copied, err := copyReferrers(t.Context(), srcRepo, dstRepo, digest.String(), crane.GetOptions())
+	if err != nil {
+		t.Fatalf("copyReferrers() error = %v", err)
+	}
+	want := []string{sbom, sbomSignature, signatureDigest.String()}
+	slices.Sort(want)
+	if !slices.Equal(copied, want) {
+		t.Errorf("copyReferrers() = %v, want %v", copied, want)
+	}
+
+	referrers, err := remote.Referrers(dstRepo.Digest(digest.String()))
+	if err != nil {
+		t.Fatalf("failed to list destination referrers: %v", err)
+	}
+	manifest, err := referrers.IndexManifest()
+	if err != nil {
+		t.Fatalf("failed to list destination referrers: %v", err)
+	}
+	if len(manifest.Manifests) != 1 || manifest.Manifests[0].Digest.String() != sbom {
+		t.Errorf("destination referrers = %v, want %s", manifest.Manifests, sbom)
+	}
+	if got, err := crane.Digest(cosignTag(dstRepo, digest, ".sig").String()); err != nil || got != signatureDigest.String() {
+		t.Errorf("destination signature = %s, %v, want %s", got, err, signatureDigest)
+	}
+
+	// Copying again is a no-op.
+	if again, err := copyReferrers(t.Context(), srcRepo, dstRepo, digest.String(), crane.GetOptions()); err != nil || !slices.Equal(again, want) {
+		t.Errorf("copyReferrers() again = %v, %v, want %v", again, err, want)
+	}
+}
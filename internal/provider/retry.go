@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var defaultRetryableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryModel describes the retry block in the provider configuration.
+type retryModel struct {
+	MaxAttempts          types.Int64  `tfsdk:"max_attempts"`
+	InitialBackoff       types.String `tfsdk:"initial_backoff"`
+	MaxBackoff           types.String `tfsdk:"max_backoff"`
+	RetryableStatusCodes types.List   `tfsdk:"retryable_status_codes"`
+}
+
+// retryPolicy controls how registry requests are retried.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	statusCodes    map[int]bool
+}
+
+func newRetryPolicy(ctx context.Context, m retryModel) (*retryPolicy, error) {
+	policy := &retryPolicy{
+		maxAttempts:    5,
+		initialBackoff: time.Second,
+		maxBackoff:     30 * time.Second,
+		statusCodes:    map[int]bool{},
+	}
+
+	if !m.MaxAttempts.IsNull() {
+		if m.MaxAttempts.ValueInt64() < 1 {
+			return nil, fmt.Errorf("max_attempts must be at least 1")
+		}
+		policy.maxAttempts = int(m.MaxAttempts.ValueInt64())
+	}
+	if !m.InitialBackoff.IsNull() {
+		d, err := time.ParseDuration(m.InitialBackoff.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("invalid initial_backoff: %w", err)
+		}
+		policy.initialBackoff = d
+	}
+	if !m.MaxBackoff.IsNull() {
+		d, err := time.ParseDuration(m.MaxBackoff.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_backoff: %w", err)
+		}
+		policy.maxBackoff = d
+	}
+	if policy.maxBackoff < policy.initialBackoff {
+		return nil, fmt.Errorf("max_backoff must not be less than initial_backoff")
+	}
+
+	codes := defaultRetryableStatusCodes
+	if !m.RetryableStatusCodes.IsNull() {
+		var configured []int64
+		if diags := m.RetryableStatusCodes.ElementsAs(ctx, &configured, false); diags.HasError() {
+			return nil, fmt.Errorf("reading retryable_status_codes")
+		}
+		codes = make([]int, 0, len(configured))
+		for _, code := range configured {
+			codes = append(codes, int(code))
+		}
+	}
+	for _, code := range codes {
+		policy.statusCodes[code] = true
+	}
+
+	return policy, nil
+}
+
+// backoff returns the delay before the given retry, starting at 1 for the first retry.
+func (p *retryPolicy) backoff(retry int) time.Duration {
+	delay := p.initialBackoff
+	for i := 1; i < retry && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.maxBackoff)
+}
+
+// disableBuiltinStatusRetries stops go-containerregistry from retrying status codes itself,
+// so that the provider's retry policy is not multiplied by the library's for them. The library
+// still makes up to three attempts around the whole policy for temporary network errors, which
+// cannot be turned off without also losing its authentication handshake.
+func disableBuiltinStatusRetries(o *crane.Options) {
+	o.Remote = append(o.Remote, remote.WithRetryStatusCodes())
+}
+
+// retryTransport retries failed requests according to a retryPolicy, honoring the
+// Retry-After header of throttled and unavailable responses.
+type retryTransport struct {
+	inner  http.RoundTripper
+	policy retryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests with a body that cannot be replayed (such as streamed blob uploads) are
+	// sent once; go-containerregistry retries those uploads itself.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return t.inner.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.inner.RoundTrip(attemptReq)
+		if attempt >= t.policy.maxAttempts || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		delay := t.policy.backoff(attempt)
+		if err == nil {
+			if !t.policy.statusCodes[resp.StatusCode] {
+				return resp, nil
+			}
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if retryAfter > t.policy.maxBackoff {
+					// The registry asked us to wait longer than we are willing to.
+					return resp, nil
+				}
+				delay = max(delay, retryAfter)
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// flakyHandler fails the first failures manifest requests with status before serving them.
+func flakyHandler(inner http.Handler, failures int32, status int, retryAfter string) (http.Handler, *atomic.Int32) {
+	var calls atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") && calls.Add(1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}), &calls
+}
+
+// testRegistry returns an in-memory registry that does not log requests.
+func testRegistry() http.Handler {
+	return registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+}
+
+func TestRetryTransport(t *testing.T) {
+	// Both servers share the same in-memory registry, so images seeded through one are
+	// served by the other.
+	reg := testRegistry()
+	seed := httptest.NewServer(reg)
+	defer seed.Close()
+	handler, calls := flakyHandler(reg, 2, http.StatusBadGateway, "")
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	ref := strings.TrimPrefix(server.URL, "http://") + "/app:latest"
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	if err := crane.Push(img, strings.TrimPrefix(seed.URL, "http://")+"/app:latest"); err != nil {
+		t.Fatalf("failed to seed image: %v", err)
+	}
+
+	policy, err := newRetryPolicy(t.Context(), retryModel{
+		MaxAttempts:    types.Int64Value(3),
+		InitialBackoff: types.StringValue("10ms"),
+		MaxBackoff:     types.StringValue("20ms"),
+		RetryableStatusCodes: types.ListValueMust(types.Int64Type, []attr.Value{
+			types.Int64Value(http.StatusBadGateway),
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transport, err := newTransport(transportConfig{retry: policy})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts := []crane.Option{crane.WithTransport(transport), disableBuiltinStatusRetries}
+
+	calls.Store(0)
+	if _, err := crane.Digest(ref, opts...); err != nil {
+		t.Fatalf("expected the request to succeed after retries: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 manifest requests, got %d", calls.Load())
+	}
+
+	calls.Store(-5)
+	if _, err := crane.Digest(ref, opts...); err == nil {
+		t.Error("expected the request to fail once max_attempts is exhausted")
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	handler, calls := flakyHandler(testRegistry(), 100, http.StatusTooManyRequests, "3600")
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	policy, err := newRetryPolicy(t.Context(), retryModel{InitialBackoff: types.StringValue("10ms")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transport, err := newTransport(transportConfig{retry: policy})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	_, err = crane.Digest(strings.TrimPrefix(server.URL, "http://")+"/app:latest", crane.WithTransport(transport), disableBuiltinStatusRetries)
+	if err == nil {
+		t.Fatal("expected a throttled request to fail")
+	}
+	// crane.Digest falls back from HEAD to GET, neither of which should be retried.
+	if calls.Load() != 2 || time.Since(start) > 5*time.Second {
+		t.Errorf("expected a Retry-After beyond max_backoff not to be retried, got %d requests", calls.Load())
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy, err := newRetryPolicy(t.Context(), retryModel{
+		InitialBackoff: types.StringValue("1s"),
+		MaxBackoff:     types.StringValue("5s"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range expected {
+		if actual := policy.backoff(i + 1); actual != delay {
+			t.Errorf("backoff(%d) = %s, expected %s", i+1, actual, delay)
+		}
+	}
+
+	if _, err := newRetryPolicy(t.Context(), retryModel{MaxAttempts: types.Int64Value(0)}); err == nil {
+		t.Error("expected an error for max_attempts below 1")
+	}
+	if _, err := newRetryPolicy(t.Context(), retryModel{MaxBackoff: types.StringValue("10ms")}); err == nil {
+		t.Error("expected an error for max_backoff below initial_backoff")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("120"); !ok || d != 2*time.Minute {
+		t.Errorf("unexpected result for seconds: %s %t", d, ok)
+	}
+	if d, ok := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); !ok || d < 59*time.Minute {
+		t.Errorf("unexpected result for HTTP date: %s %t", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("expected an invalid value to be ignored")
+	}
+}
@@ -0,0 +1,284 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &TagResource{}
+var _ resource.ResourceWithConfigure = &TagResource{}
+
+func NewTagResource() resource.Resource {
+	return &TagResource{}
+}
+
+// TagResource defines the resource implementation.
+type TagResource struct {
+	client *registryClient
+}
+
+// TagResourceModel describes the resource data model.
+type TagResourceModel struct {
+	Id         types.String `tfsdk:"id"`
+	Image      types.String `tfsdk:"image"`
+	Tags       types.Set    `tfsdk:"tags"`
+	Digest     types.String `tfsdk:"digest"`
+	TagDigests types.Map    `tfsdk:"tag_digests"`
+}
+
+func (r *TagResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tag"
+}
+
+func (r *TagResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Apply tags to an image that already exists in a repository, equivalent to ` + "`crane tag`" + `.
+
+Tags are applied by uploading the image manifest under each tag, so no layers are copied. Tags moved to a different image outside of Terraform are detected and moved back on the next apply. Removing a tag from ` + "`tags`" + ` or destroying the resource does not delete tags from the repository.`,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Equivalent to `image`.",
+			},
+			"image": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The image to tag, pinned by digest (`registry/repo@sha256:...`).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"tags": schema.SetAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The tags to point at `image` within its repository.",
+			},
+			"digest": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The digest of `image`.",
+			},
+			"tag_digests": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The digest each tag pointed at when last read from the registry.",
+			},
+		},
+	}
+}
+
+func (r *TagResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*registryClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *registryClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *TagResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data TagResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var tags []string
+	resp.Diagnostics.Append(data.Tags.ElementsAs(ctx, &tags, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.applyTags(ctx, &data, tags)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TagResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data TagResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	image := data.Image.ValueString()
+	o := crane.GetOptions(r.client.options...)
+	ref, err := name.NewDigest(image, o.Name...)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error parsing image reference from state",
+			fmt.Sprintf("Unable to parse image reference '%s': %s", image, err),
+		)
+		return
+	}
+
+	if _, err := remote.Head(ref, o.Remote...); err != nil {
+		var remoteErr *transport.Error
+		if errors.As(err, &remoteErr) && remoteErr.StatusCode == http.StatusNotFound {
+			resp.Diagnostics.AddWarning(
+				"Image Not Found",
+				fmt.Sprintf("Image '%s' not found in the registry. It may have been deleted.", image),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error fetching image from registry",
+			fmt.Sprintf("Unable to fetch image '%s' from the registry: %s", image, err),
+		)
+		return
+	}
+
+	var tags []string
+	resp.Diagnostics.Append(data.Tags.ElementsAs(ctx, &tags, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tagDigests, err := readTagDigests(ctx, ref.Context(), tags, o)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading tag",
+			fmt.Sprintf("Unable to read the tags of '%s': %s", image, err),
+		)
+		return
+	}
+
+	// Tags that were deleted or moved to another image are dropped from state, so that the
+	// next plan puts them back.
+	current := []string{}
+	for _, tag := range tags {
+		digest, ok := tagDigests[tag]
+		switch {
+		case !ok:
+			continue
+		case digest != ref.DigestStr():
+			tflog.Debug(ctx, fmt.Sprintf("Tag '%s' was moved to '%s'", tag, digest))
+		default:
+			current = append(current, tag)
+		}
+	}
+
+	tagSet, diags := types.SetValueFrom(ctx, types.StringType, current)
+	resp.Diagnostics.Append(diags...)
+	tagDigestMap, diags := types.MapValueFrom(ctx, types.StringType, tagDigests)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Tags = tagSet
+	data.TagDigests = tagDigestMap
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TagResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state TagResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var planned, existing []string
+	resp.Diagnostics.Append(data.Tags.ElementsAs(ctx, &planned, false)...)
+	resp.Diagnostics.Append(state.Tags.ElementsAs(ctx, &existing, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only tags that are new or were moved away from the image need to be written.
+	applied := map[string]bool{}
+	for _, tag := range existing {
+		applied[tag] = true
+	}
+	var tags []string
+	for _, tag := range planned {
+		if !applied[tag] {
+			tags = append(tags, tag)
+		}
+	}
+
+	resp.Diagnostics.Append(r.applyTags(ctx, &data, tags)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TagResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// applyTags points each of tags at the image in data by uploading its manifest under the
+// tag, and records the computed attributes for all tags in data.
+func (r *TagResource) applyTags(ctx context.Context, data *TagResourceModel, tags []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	image := data.Image.ValueString()
+	o := crane.GetOptions(r.client.options...)
+	ref, err := name.NewDigest(image, o.Name...)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("image"),
+			"Image must be pinned by digest",
+			fmt.Sprintf("Unable to parse '%s' as a digest reference (registry/repo@sha256:...): %s", image, err),
+		)
+		return diags
+	}
+
+	if err := tagImage(ctx, ref, tags, o); err != nil {
+		diags.AddError(
+			"Error tagging image",
+			fmt.Sprintf("Unable to tag '%s': %s", image, err),
+		)
+		return diags
+	}
+
+	var allTags []string
+	diags.Append(data.Tags.ElementsAs(ctx, &allTags, false)...)
+	tagDigests := map[string]string{}
+	for _, tag := range allTags {
+		tagDigests[tag] = ref.DigestStr()
+	}
+	tagDigestMap, d := types.MapValueFrom(ctx, types.StringType, tagDigests)
+	diags.Append(d...)
+
+	data.Id = types.StringValue(image)
+	data.Digest = types.StringValue(ref.DigestStr())
+	data.TagDigests = tagDigestMap
+	return diags
+}
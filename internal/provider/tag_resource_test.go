@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	testutils "github.com/adam-tylr/terraform-provider-crane/testing"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccTagResource(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	tags := testutils.CopyImagesToRepository(t, repo)
+
+	digest, err := crane.Digest(fmt.Sprintf("%s:%s", repo, tags[0]))
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	otherDigest, err := crane.Digest(fmt.Sprintf("%s:%s", repo, tags[1]))
+	if err != nil {
+		t.Fatalf("failed to read digest: %v", err)
+	}
+	image := fmt.Sprintf("%s@%s", repo, digest)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccTag(image, "staging"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_tag.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(image),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_tag.test",
+						tfjsonpath.New("digest"),
+						knownvalue.StringExact(digest),
+					),
+					statecheck.ExpectKnownValue(
+						"crane_tag.test",
+						tfjsonpath.New("tag_digests"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"staging": knownvalue.StringExact(digest),
+						}),
+					),
+				},
+			},
+			// Add a tag
+			{
+				Config: testAccTag(image, "staging", "prod"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_tag.test",
+						tfjsonpath.New("tag_digests"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"staging": knownvalue.StringExact(digest),
+							"prod":    knownvalue.StringExact(digest),
+						}),
+					),
+				},
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_tag.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+			},
+			// Move a tag outside of Terraform and expect it to be moved back
+			{
+				PreConfig: func() {
+					if err := crane.Tag(fmt.Sprintf("%s@%s", repo, otherDigest), "prod"); err != nil {
+						t.Fatalf("failed to move tag: %v", err)
+					}
+				},
+				Config: testAccTag(image, "staging", "prod"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(
+							"crane_tag.test",
+							plancheck.ResourceActionUpdate,
+						),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"crane_tag.test",
+						tfjsonpath.New("tag_digests"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"staging": knownvalue.StringExact(digest),
+							"prod":    knownvalue.StringExact(digest),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccTagResourceRequiresDigest(t *testing.T) {
+	repo, teardown := testutils.CreateRepository(t)
+	defer teardown()
+	tags := testutils.CopyImagesToRepository(t, repo)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTag(fmt.Sprintf("%s:%s", repo, tags[0]), "prod"),
+				ExpectError: regexp.MustCompile("Image must be pinned by digest"),
+			},
+		},
+	})
+}
+
+func testAccTag(image string, tags ...string) string {
+	quoted := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		quoted = append(quoted, fmt.Sprintf("%q", tag))
+	}
+	return fmt.Sprintf(`
+resource "crane_tag" "test" {
+  image = %q
+  tags = [%s]
+}
+`, image, strings.Join(quoted, ", "))
+}
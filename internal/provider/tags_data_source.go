@@ -22,7 +22,7 @@ func NewTagsDataSource() datasource.DataSource {
 
 // TagsDataSource defines the data source implementation.
 type TagsDataSource struct {
-	options []crane.Option
+	client *registryClient
 }
 
 // TagsDataSourceModel describes the data source data model.
@@ -73,22 +73,21 @@ func (d *TagsDataSource) Configure(ctx context.Context, req datasource.Configure
 		return
 	}
 
-	options, ok := req.ProviderData.([]crane.Option)
+	client, ok := req.ProviderData.(*registryClient)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *[]crane.Option, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *registryClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.options = options
+	d.client = client
 }
 
 func (d *TagsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var data TagsDataSourceModel
-	o := crane.GetOptions(d.options...)
 
 	// Read Terraform configuration data into the model
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -98,6 +97,7 @@ func (d *TagsDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	}
 
 	src := data.Repository.ValueString()
+	o := crane.GetOptions(d.client.options...)
 	repo, err := name.NewRepository(src, o.Name...)
 	if err != nil {
 		resp.Diagnostics.AddError(fmt.Sprintf("Error parsing repository name: %s", src), err.Error())
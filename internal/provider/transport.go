@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// transportConfig describes how the provider connects to registries.
+type transportConfig struct {
+	// insecureRegistries lists registry hosts that may be reached over plain HTTP or
+	// with unverified TLS certificates.
+	insecureRegistries map[string]bool
+	caCertificate      string
+	clientCertificate  string
+	clientKey          string
+	retry              *retryPolicy
+}
+
+// newTransport builds the HTTP transport shared by all registry operations. It returns nil
+// when the configuration does not require anything beyond the default transport.
+func newTransport(cfg transportConfig) (http.RoundTripper, error) {
+	if len(cfg.insecureRegistries) == 0 && cfg.caCertificate == "" && cfg.clientCertificate == "" && cfg.clientKey == "" && cfg.retry == nil {
+		return nil, nil
+	}
+
+	transport, err := newTLSTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.retry != nil {
+		transport = &retryTransport{inner: transport, policy: *cfg.retry}
+	}
+	return transport, nil
+}
+
+// newTLSTransport builds a transport trusting the configured CA certificates, presenting
+// the configured client certificate and skipping verification for insecure registries.
+func newTLSTransport(cfg transportConfig) (http.RoundTripper, error) {
+	defaultTransport, ok := remote.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default transport type %T", remote.DefaultTransport)
+	}
+	base := defaultTransport.Clone()
+	base.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.caCertificate != "" {
+		caPEM, err := readPEM(cfg.caCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca_certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("ca_certificate does not contain any PEM encoded certificates")
+		}
+		base.TLSClientConfig.RootCAs = pool
+	}
+
+	if cfg.clientCertificate != "" || cfg.clientKey != "" {
+		if cfg.clientCertificate == "" || cfg.clientKey == "" {
+			return nil, fmt.Errorf("client_certificate and client_key must be set together")
+		}
+		certPEM, err := readPEM(cfg.clientCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("reading client_certificate: %w", err)
+		}
+		keyPEM, err := readPEM(cfg.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("reading client_key: %w", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		base.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(cfg.insecureRegistries) == 0 {
+		return base, nil
+	}
+
+	insecure := base.Clone()
+	insecure.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec // Only used for registries the user explicitly allowlisted.
+	return &hostTransport{
+		hosts:    cfg.insecureRegistries,
+		matched:  &plainHTTPTransport{inner: insecure},
+		fallback: base,
+	}, nil
+}
+
+// newInsecureRegistries normalizes the insecure_registries allowlist to registry hosts.
+func newInsecureRegistries(ctx context.Context, list types.List) (map[string]bool, error) {
+	var registries []string
+	if diags := list.ElementsAs(ctx, &registries, false); diags.HasError() {
+		return nil, fmt.Errorf("reading insecure_registries")
+	}
+
+	hosts := map[string]bool{}
+	for _, registry := range registries {
+		host, err := registryHost(registry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry %q: %w", registry, err)
+		}
+		hosts[host] = true
+	}
+	return hosts, nil
+}
+
+// hostTransport routes requests for a set of hosts to a dedicated transport.
+type hostTransport struct {
+	hosts    map[string]bool
+	matched  http.RoundTripper
+	fallback http.RoundTripper
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hosts[req.URL.Host] {
+		return t.matched.RoundTrip(req)
+	}
+	return t.fallback.RoundTrip(req)
+}
+
+// plainHTTPTransport falls back to plain HTTP for registries that do not serve HTTPS. It is
+// only used for allowlisted insecure registries, so that references to every other registry
+// keep requiring HTTPS even when they are used alongside an insecure one.
+type plainHTTPTransport struct {
+	inner http.RoundTripper
+	// plain records the hosts found to only serve plain HTTP.
+	plain sync.Map
+}
+
+func (t *plainHTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return t.inner.RoundTrip(req)
+	}
+
+	downgraded := req.Clone(req.Context())
+	downgraded.URL.Scheme = "http"
+	if _, ok := t.plain.Load(req.URL.Host); ok {
+		return t.inner.RoundTrip(downgraded)
+	}
+
+	resp, err := t.inner.RoundTrip(req)
+	if err == nil || !servesPlainHTTP(err) {
+		return resp, err
+	}
+	// The body was closed by the failed request, so it can only be sent again if it can be
+	// recreated.
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, err
+		}
+		downgraded.Body = body
+	}
+	t.plain.Store(req.URL.Host, true)
+	return t.inner.RoundTrip(downgraded)
+}
+
+// servesPlainHTTP reports whether err shows that the registry could not be reached over
+// HTTPS, either because it answered with plain HTTP or because nothing listens for HTTPS.
+func servesPlainHTTP(err error) bool {
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &recordErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// readPEM returns value itself when it is PEM content, or the contents of the file it names.
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
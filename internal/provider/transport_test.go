@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestTransportCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(testRegistry())
+	defer server.Close()
+	ref := strings.TrimPrefix(server.URL, "https://") + "/app:latest"
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+
+	if err := crane.Push(img, ref); err == nil {
+		t.Fatal("expected push without the CA certificate to fail")
+	}
+
+	transport, err := newTransport(transportConfig{caCertificate: caPEM})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := crane.Push(img, ref, crane.WithTransport(transport)); err != nil {
+		t.Fatalf("failed to push with the CA certificate: %v", err)
+	}
+}
+
+func TestTransportInsecureRegistries(t *testing.T) {
+	server := httptest.NewTLSServer(testRegistry())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	insecure, err := newInsecureRegistries(t.Context(), types.ListValueMust(types.StringType, []attr.Value{types.StringValue(host)}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transport, err := newTransport(transportConfig{insecureRegistries: insecure})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	if err := crane.Push(img, host+"/app:latest", crane.WithTransport(transport)); err != nil {
+		t.Fatalf("failed to push to insecure registry: %v", err)
+	}
+}
+
+func TestTransportDefault(t *testing.T) {
+	transport, err := newTransport(transportConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transport != nil {
+		t.Errorf("expected the default transport to be used, got %T", transport)
+	}
+
+	if _, err := newInsecureRegistries(t.Context(), types.ListNull(types.StringType)); err != nil {
+		t.Errorf("unexpected error for an unset allowlist: %v", err)
+	}
+
+	if _, err := newTransport(transportConfig{clientCertificate: "cert.pem"}); err == nil {
+		t.Error("expected an error when client_key is missing")
+	}
+}
+
+func TestTransportPlainHTTP(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	insecureHost := strings.TrimPrefix(server.URL, "http://")
+	secure := httptest.NewServer(testRegistry())
+	defer secure.Close()
+	secureHost := strings.TrimPrefix(secure.URL, "http://")
+
+	transport, err := newTransport(transportConfig{insecureRegistries: map[string]bool{insecureHost: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := &http.Client{Transport: transport}
+
+	// Requests are made over HTTPS, as they are for any registry that is not on a loopback
+	// address, and only fall back to plain HTTP for the insecure registry.
+	for range 2 {
+		resp, err := client.Get("https://" + insecureHost + "/v2/")
+		if err != nil {
+			t.Fatalf("expected the insecure registry to be reached over plain HTTP: %v", err)
+		}
+		resp.Body.Close()
+		if resp.Request.URL.Scheme != "http" {
+			t.Errorf("insecure registry reached over %s, want http", resp.Request.URL.Scheme)
+		}
+	}
+
+	// A request with a body is sent again over plain HTTP when it is the first one made.
+	transport, err = newTransport(transportConfig{insecureRegistries: map[string]bool{insecureHost: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "https://"+insecureHost+"/v2/app/blobs/uploads/", strings.NewReader("layer"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		t.Fatalf("expected a request with a body to be sent over plain HTTP: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("upload over plain HTTP status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+
+	if resp, err := client.Get("https://" + secureHost + "/v2/"); err == nil {
+		resp.Body.Close()
+		t.Error("expected a registry missing from insecure_registries to require HTTPS")
+	}
+}
@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// simpleSigningMediaType is the media type of the layers holding cosign signature payloads.
+	simpleSigningMediaType = ggcrtypes.MediaType("application/vnd.dev.cosign.simplesigning.v1+json")
+	// cosignSignatureAnnotation holds the base64 encoded signature of a payload layer.
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// cosignSignatureArtifactType is the artifact type of signatures stored as referrers.
+	cosignSignatureArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// simpleSigningType is the type of the payload of a container image signature.
+	simpleSigningType = "cosign container image signature"
+)
+
+// verifyModel describes the signatures required on a source image.
+type verifyModel struct {
+	PublicKeys types.List `tfsdk:"public_keys"`
+}
+
+// publicKey is a key accepted to sign images.
+type publicKey struct {
+	key         crypto.PublicKey
+	description string
+}
+
+// simpleSigningPayload is the part of a cosign signature payload identifying the image signed.
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// publicKeys parses the PEM encoded keys of m.
+func (m *verifyModel) publicKeys(ctx context.Context) ([]publicKey, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var encoded []string
+	diags.Append(m.PublicKeys.ElementsAs(ctx, &encoded, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	keys := make([]publicKey, 0, len(encoded))
+	for i, pemKey := range encoded {
+		key, err := parsePublicKey(pemKey, i+1)
+		if err != nil {
+			diags.AddError(
+				"Error parsing public key",
+				fmt.Sprintf("Unable to parse public key %d of verify: %s", i+1, err),
+			)
+			return nil, diags
+		}
+		keys = append(keys, key)
+	}
+	return keys, diags
+}
+
+// parsePublicKey parses a PEM encoded ECDSA, RSA or Ed25519 public key, the n-th of the
+// configuration.
+func parsePublicKey(pemKey string, n int) (publicKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return publicKey{}, errors.New("no PEM block found")
+	}
+
+	var key crypto.PublicKey
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return publicKey{}, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return publicKey{}, err
+	}
+
+	var algorithm string
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		algorithm = "ECDSA " + k.Curve.Params().Name
+	case *rsa.PublicKey:
+		algorithm = fmt.Sprintf("RSA %d", k.N.BitLen())
+	case ed25519.PublicKey:
+		algorithm = "Ed25519"
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %T", key)
+	}
+	fingerprint := sha256.Sum256(block.Bytes)
+	return publicKey{
+		key:         key,
+		description: fmt.Sprintf("key %d (%s, sha256:%s)", n, algorithm, hex.EncodeToString(fingerprint[:])[:16]),
+	}, nil
+}
+
+// verifyPayload reports whether signature is a valid signature of payload by key. ECDSA and
+// RSA signatures are over the SHA-256 digest of the payload, as made by cosign.
+func verifyPayload(key crypto.PublicKey, payload []byte, signature []byte) bool {
+	digest := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil ||
+			rsa.VerifyPSS(k, crypto.SHA256, digest[:], signature, nil) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, signature)
+	default:
+		return false
+	}
+}
+
+// verifyImageSignature checks that the image with digest in repo has a cosign signature made
+// with one of keys. Signatures are looked up under the cosign signature tag and among the
+// referrers of the image.
+func verifyImageSignature(ctx context.Context, repo name.Repository, digest v1.Hash, keys []publicKey, o crane.Options) error {
+	signatures, err := signatureManifests(repo, digest, o)
+	if err != nil {
+		return err
+	}
+
+	tried := make([]string, 0, len(keys))
+	for _, key := range keys {
+		tried = append(tried, key.description)
+	}
+
+	found := 0
+	for _, img := range signatures {
+		manifest, err := img.Manifest()
+		if err != nil {
+			return fmt.Errorf("failed to read signature manifest: %w", err)
+		}
+		for _, layer := range manifest.Layers {
+			encoded, ok := layer.Annotations[cosignSignatureAnnotation]
+			if layer.MediaType != simpleSigningMediaType || !ok {
+				continue
+			}
+			found++
+			signature, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				tflog.Debug(ctx, fmt.Sprintf("Ignoring signature with invalid encoding in layer '%s': %s", layer.Digest, err))
+				continue
+			}
+			payload, err := signaturePayload(img, layer.Digest)
+			if err != nil {
+				return err
+			}
+			var parsed simpleSigningPayload
+			if err := json.Unmarshal(payload, &parsed); err != nil {
+				tflog.Debug(ctx, fmt.Sprintf("Ignoring signature with invalid payload in layer '%s': %s", layer.Digest, err))
+				continue
+			}
+			if parsed.Critical.Type != simpleSigningType || parsed.Critical.Image.DockerManifestDigest != digest.String() {
+				tflog.Debug(ctx, fmt.Sprintf("Ignoring signature of '%s' in layer '%s'", parsed.Critical.Image.DockerManifestDigest, layer.Digest))
+				continue
+			}
+			for _, key := range keys {
+				if verifyPayload(key.key, payload, signature) {
+					tflog.Debug(ctx, fmt.Sprintf("Signature of '%s' verified with %s", repo.Digest(digest.String()), key.description))
+					return nil
+				}
+			}
+		}
+	}
+
+	if found == 0 {
+		return fmt.Errorf("no signatures found for '%s' (keys tried: %s)", repo.Digest(digest.String()), strings.Join(tried, ", "))
+	}
+	return fmt.Errorf("none of the %d signatures found for '%s' could be verified (keys tried: %s)", found, repo.Digest(digest.String()), strings.Join(tried, ", "))
+}
+
+// signatureManifests returns the manifests that may hold cosign signatures of digest in repo.
+func signatureManifests(repo name.Repository, digest v1.Hash, o crane.Options) ([]v1.Image, error) {
+	var signatures []v1.Image
+
+	tag := cosignTag(repo, digest, ".sig")
+	img, err := remote.Image(tag, o.Remote...)
+	var remoteErr *transport.Error
+	switch {
+	case err == nil:
+		signatures = append(signatures, img)
+	case errors.As(err, &remoteErr) && remoteErr.StatusCode == http.StatusNotFound:
+	default:
+		return nil, fmt.Errorf("failed to read signatures '%s': %w", tag, err)
+	}
+
+	referrers, err := remote.Referrers(repo.Digest(digest.String()), o.Remote...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referrers of '%s': %w", repo.Digest(digest.String()), err)
+	}
+	manifest, err := referrers.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referrers of '%s': %w", repo.Digest(digest.String()), err)
+	}
+	for _, referrer := range manifest.Manifests {
+		if referrer.ArtifactType != cosignSignatureArtifactType {
+			continue
+		}
+		img, err := remote.Image(repo.Digest(referrer.Digest.String()), o.Remote...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signatures '%s': %w", repo.Digest(referrer.Digest.String()), err)
+		}
+		signatures = append(signatures, img)
+	}
+	return signatures, nil
+}
+
+// signaturePayload reads the payload layer with digest from img.
+func signaturePayload(img v1.Image, digest v1.Hash) ([]byte, error) {
+	layer, err := img.LayerByDigest(digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature payload '%s': %w", digest, err)
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature payload '%s': %w", digest, err)
+	}
+	defer rc.Close()
+	payload, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature payload '%s': %w", digest, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+)
+
+// signImage pushes a cosign signature of digest in repo made with key, the way
+// `cosign sign --key` stores it.
+func signImage(t *testing.T, repo name.Repository, digest v1.Hash, key crypto.Signer) {
+	t.Helper()
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, repo.Name(), digest))
+	var signature []byte
+	var err error
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		signature, err = key.Sign(rand.Reader, payload, crypto.Hash(0))
+	} else {
+		hash := sha256.Sum256(payload)
+		signature, err = key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatalf("failed to sign payload: %v", err)
+	}
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(payload, simpleSigningMediaType),
+		Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+	})
+	if err != nil {
+		t.Fatalf("failed to create signature image: %v", err)
+	}
+	if err := remote.Write(cosignTag(repo, digest, ".sig"), img); err != nil {
+		t.Fatalf("failed to push signature: %v", err)
+	}
+}
+
+// encodePublicKey returns the PEM encoding of the public key of key.
+func encodePublicKey(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestVerifyImageSignature(t *testing.T) {
+	server := httptest.NewServer(testRegistry())
+	defer server.Close()
+	repo, err := name.NewRepository(strings.TrimPrefix(server.URL, "http://") + "/app")
+	if err != nil {
+		t.Fatalf("failed to parse repository: %v", err)
+	}
+
+	push := func(tag string) v1.Hash {
+		img, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatalf("failed to create image: %v", err)
+		}
+		if err := remote.Write(repo.Tag(tag), img); err != nil {
+			t.Fatalf("failed to push image: %v", err)
+		}
+		digest, err := img.Digest()
+		if err != nil {
+			t.Fatalf("failed to read digest: %v", err)
+		}
+		return digest
+	}
+	signed := push("signed")
+	unsigned := push("unsigned")
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	signImage(t, repo, signed, signer)
+	signerKey, err := parsePublicKey(encodePublicKey(t, signer), 2)
+	if err != nil {
+		t.Fatalf("parsePublicKey() error = %v", err)
+	}
+	otherKey, err := parsePublicKey(encodePublicKey(t, other), 1)
+	if err != nil {
+		t.Fatalf("parsePublicKey() error = %v", err)
+	}
+
+	o := crane.GetOptions()
+	if err := verifyImageSignature(t.Context(), repo, signed, []publicKey{otherKey, signerKey}, o); err != nil {
+		t.Errorf("verifyImageSignature() with the signing key error = %v", err)
+	}
+
+	err = verifyImageSignature(t.Context(), repo, signed, []publicKey{otherKey}, o)
+	if err == nil || !strings.Contains(err.Error(), "none of the 1 signatures") || !strings.Contains(err.Error(), "key 1 (ECDSA P-256, sha256:") {
+		t.Errorf("verifyImageSignature() with another key error = %v, want the signature to be rejected and the key listed", err)
+	}
+
+	err = verifyImageSignature(t.Context(), repo, unsigned, []publicKey{signerKey}, o)
+	if err == nil || !strings.Contains(err.Error(), "no signatures found") {
+		t.Errorf("verifyImageSignature() of an unsigned image error = %v, want no signatures found", err)
+	}
+
+	// A valid signature of another image copied under the signature tag is not accepted.
+	sig, err := remote.Image(cosignTag(repo, signed, ".sig"))
+	if err != nil {
+		t.Fatalf("failed to read signature: %v", err)
+	}
+	if err := remote.Write(cosignTag(repo, unsigned, ".sig"), sig); err != nil {
+		t.Fatalf("failed to copy signature: %v", err)
+	}
+	if err := verifyImageSignature(t.Context(), repo, unsigned, []publicKey{signerKey}, o); err == nil {
+		t.Error("verifyImageSignature() with the signature of another image succeeded, want an error")
+	}
+}
+
+func TestVerifyPayload(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	payload := []byte(`{"critical":{}}`)
+	hash := sha256.Sum256(payload)
+	for _, key := range []crypto.Signer{ecdsaKey, rsaKey, ed25519Key} {
+		t.Run(fmt.Sprintf("%T", key), func(t *testing.T) {
+			var signature []byte
+			var err error
+			if _, ok := key.(ed25519.PrivateKey); ok {
+				signature, err = key.Sign(rand.Reader, payload, crypto.Hash(0))
+			} else {
+				signature, err = key.Sign(rand.Reader, hash[:], crypto.SHA256)
+			}
+			if err != nil {
+				t.Fatalf("failed to sign: %v", err)
+			}
+			parsed, err := parsePublicKey(encodePublicKey(t, key), 1)
+			if err != nil {
+				t.Fatalf("parsePublicKey() error = %v", err)
+			}
+			if !verifyPayload(parsed.key, payload, signature) {
+				t.Error("verifyPayload() = false, want true")
+			}
+			if verifyPayload(parsed.key, []byte(`{"critical":{"tampered":true}}`), signature) {
+				t.Error("verifyPayload() of a tampered payload = true, want false")
+			}
+		})
+	}
+}
+
+func TestParsePublicKeyInvalid(t *testing.T) {
+	for _, key := range []string{"", "not a key", "-----BEGIN CERTIFICATE-----\nMA==\n-----END CERTIFICATE-----\n"} {
+		if _, err := parsePublicKey(key, 1); err == nil {
+			t.Errorf("parsePublicKey(%q) succeeded, want an error", key)
+		}
+	}
+}
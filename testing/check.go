@@ -6,17 +6,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	tfjson "github.com/hashicorp/terraform-json"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ecr"
-	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
 	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
@@ -77,46 +73,26 @@ func (e checkRemoteImage) CheckState(ctx context.Context, req statecheck.CheckSt
 	}
 	platformValue, _ := tfjsonpath.Traverse(resource.AttributeValues, tfjsonpath.New("platform"))
 
-	repo, _ := name.ParseReference(id)
-
-	// Load the AWS configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion("us-east-1"))
+	repo, err := name.ParseReference(id)
 	if err != nil {
-		resp.Error = err
+		resp.Error = fmt.Errorf("failed to parse id %s: %w", id, err)
 		return
 	}
 
-	// Create an ECR client
-	svc := ecr.NewFromConfig(cfg)
-
-	input := &ecr.BatchGetImageInput{
-		RepositoryName: aws.String(repo.Context().RepositoryStr()),
-		ImageIds: []types.ImageIdentifier{
-			{
-				ImageTag: aws.String(repo.Identifier()),
-			},
-		},
-	}
-	if strings.HasPrefix(repo.Identifier(), "sha256:") {
-		input.ImageIds = []types.ImageIdentifier{
-			{
-				ImageDigest: aws.String(repo.Identifier()),
-			},
-		}
-	}
-	result, err := svc.BatchGetImage(context.TODO(), input)
+	desc, err := remote.Head(repo)
 	if err != nil {
-		resp.Error = fmt.Errorf("failed to get image from ECR: %w", err)
-		return
-	}
-	if len(result.Images) == 0 {
-		resp.Error = fmt.Errorf("image %s not found in repository %s", repo.Identifier(), repo.Context().RepositoryStr())
+		resp.Error = fmt.Errorf("image %s not found in repository %s: %w", repo.Identifier(), repo.Context().RepositoryStr(), err)
 		return
 	}
 
 	if platformValue == nil {
+		rawManifest, err := crane.Manifest(id)
+		if err != nil {
+			resp.Error = fmt.Errorf("failed to get image manifest: %w", err)
+			return
+		}
 		var m manifest
-		err = json.Unmarshal([]byte(*result.Images[0].ImageManifest), &m)
+		err = json.Unmarshal(rawManifest, &m)
 		if err != nil {
 			resp.Error = fmt.Errorf("failed to unmarshal image manifest: %w", err)
 			return
@@ -141,8 +117,8 @@ func (e checkRemoteImage) CheckState(ctx context.Context, req statecheck.CheckSt
 		p, _ := v1.ParsePlatform(platform)
 		opts := []crane.Option{crane.WithPlatform(p)}
 		d, _ := crane.Digest(id, opts...)
-		if *result.Images[0].ImageId.ImageDigest != d {
-			resp.Error = fmt.Errorf("image digest does not match expected digest: %s", *result.Images[0].ImageId.ImageDigest)
+		if desc.Digest.String() != d {
+			resp.Error = fmt.Errorf("image digest does not match expected digest: %s", desc.Digest)
 			return
 		}
 	}
@@ -0,0 +1,191 @@
+// Copyright (c) HashiCorp, Inc.
+
+package testing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Fault describes a failure injected into requests for a repository of the test registry.
+type Fault struct {
+	// Method limits the fault to requests with this HTTP method. Empty matches any method.
+	Method string
+	// Path is a regular expression matched against the request path and query within the
+	// repository (e.g. `manifests/latest`, `blobs/uploads/` or `mount=`).
+	Path string
+	// Skip lets the first Skip matching requests through unchanged.
+	Skip int
+	// Times limits the fault to that many matching requests after Skip. Zero injects the
+	// fault into every matching request.
+	Times int
+
+	// Status, when set, is returned with a registry error instead of serving the request.
+	Status int
+	// RetryAfter is sent as the Retry-After header of Status responses.
+	RetryAfter string
+	// Latency delays the response.
+	Latency time.Duration
+	// TruncateBody serves only the first half of the response body, while still announcing
+	// its full length.
+	TruncateBody bool
+	// WrongDigest replaces the Docker-Content-Digest response header with a digest that does
+	// not match the content.
+	WrongDigest bool
+}
+
+// InjectFaults injects faults into requests for the repository of imageRef in the test
+// registry, and returns a function that removes them. imageRef may be a repository or an
+// image reference. Faults are also removed when the test ends.
+// Each request is affected by the first matching fault only.
+func InjectFaults(t *testing.T, imageRef string, faults ...Fault) func() {
+	t.Helper()
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		t.Fatalf("failed to parse image %s: %v", imageRef, err)
+	}
+	repo := ref.Context()
+	if _, err := Registry(); err != nil {
+		t.Fatalf("failed to start test registry: %v", err)
+	}
+
+	active := make([]*activeFault, 0, len(faults))
+	for _, fault := range faults {
+		path, err := regexp.Compile(fault.Path)
+		if err != nil {
+			t.Fatalf("invalid fault path %q: %v", fault.Path, err)
+		}
+		active = append(active, &activeFault{Fault: fault, path: path})
+	}
+
+	testRegistry.mu.Lock()
+	testRegistry.faults[repo.RepositoryStr()] = append(testRegistry.faults[repo.RepositoryStr()], active...)
+	testRegistry.mu.Unlock()
+
+	remove := func() {
+		testRegistry.mu.Lock()
+		defer testRegistry.mu.Unlock()
+		var remaining []*activeFault
+		for _, fault := range testRegistry.faults[repo.RepositoryStr()] {
+			if !containsFault(active, fault) {
+				remaining = append(remaining, fault)
+			}
+		}
+		testRegistry.faults[repo.RepositoryStr()] = remaining
+	}
+	t.Cleanup(remove)
+	return remove
+}
+
+func containsFault(faults []*activeFault, fault *activeFault) bool {
+	for _, f := range faults {
+		if f == fault {
+			return true
+		}
+	}
+	return false
+}
+
+// activeFault is a Fault injected into the test registry, along with the number of
+// requests it matched so far.
+type activeFault struct {
+	Fault
+	path    *regexp.Regexp
+	matched int
+}
+
+// fault returns the fault to inject into req for repository, if any.
+func (r *repositoryRegistry) fault(repository string, req *http.Request) *activeFault {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target := strings.TrimPrefix(req.URL.Path, fmt.Sprintf("/v2/%s/", repository))
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+	for _, fault := range r.faults[repository] {
+		if fault.Method != "" && fault.Method != req.Method {
+			continue
+		}
+		if !fault.path.MatchString(target) {
+			continue
+		}
+		fault.matched++
+		if fault.matched <= fault.Skip {
+			return nil
+		}
+		if fault.Times > 0 && fault.matched > fault.Skip+fault.Times {
+			continue
+		}
+		return fault
+	}
+	return nil
+}
+
+// serve answers req according to the fault, using inner to serve the request when the
+// fault alters a real response.
+func (f *activeFault) serve(w http.ResponseWriter, req *http.Request, inner http.Handler) {
+	if f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	if f.Status != 0 {
+		if f.RetryAfter != "" {
+			w.Header().Set("Retry-After", f.RetryAfter)
+		}
+		writeRegistryError(w, f.Status, fmt.Sprintf("injected fault for %s %s", req.Method, req.URL.Path))
+		return
+	}
+	if !f.TruncateBody && !f.WrongDigest {
+		inner.ServeHTTP(w, req)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	inner.ServeHTTP(rec, req)
+	for key, values := range rec.Header() {
+		w.Header()[key] = values
+	}
+	if f.WrongDigest && w.Header().Get("Docker-Content-Digest") != "" {
+		w.Header().Set("Docker-Content-Digest", "sha256:"+strings.Repeat("0", 64))
+	}
+	body := rec.Body.Bytes()
+	if f.TruncateBody && req.Method != http.MethodHead {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		body = body[:len(body)/2]
+	}
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(body)
+}
+
+// writeRegistryError writes a registry API error response with the error code matching
+// status.
+func writeRegistryError(w http.ResponseWriter, status int, message string) {
+	code := "UNKNOWN"
+	switch status {
+	case http.StatusUnauthorized:
+		code = "UNAUTHORIZED"
+	case http.StatusForbidden:
+		code = "DENIED"
+	case http.StatusNotFound:
+		code = "NAME_UNKNOWN"
+	case http.StatusTooManyRequests:
+		code = "TOOMANYREQUESTS"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"errors":[{"code":%q,"message":%q}]}`, code, message)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) HashiCorp, Inc.
+
+package testing
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// sourceImages are seeded into the test registry as multi-platform images, for use as
+// image sources through CreateSourceRef.
+var sourceImages = []string{
+	"docker/library/alpine:latest",
+	"docker/library/alpine:3",
+	"docker/library/alpine:3.21",
+	"nginx/nginx:latest",
+	"nginx/nginx:alpine",
+}
+
+// singlePlatformImages are seeded into the test registry as plain linux/amd64 image
+// manifests, without an index, for use as image sources through CreateSourceRef.
+var singlePlatformImages = []string{
+	"docker/library/busybox:latest",
+}
+
+// sourcePlatforms are the platforms of every seeded multi-platform source image.
+var sourcePlatforms = []v1.Platform{
+	{OS: "linux", Architecture: "amd64"},
+	{OS: "linux", Architecture: "arm64"},
+}
+
+var (
+	testRegistryOnce sync.Once
+	testRegistry     *repositoryRegistry
+	testRegistryErr  error
+)
+
+// Registry returns the host of the in-memory registry shared by all acceptance tests,
+// starting it and seeding the source images on first use.
+func Registry() (string, error) {
+	testRegistryOnce.Do(func() {
+		testRegistry = &repositoryRegistry{
+			inner:        registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.WithReferrersSupport(true)),
+			repositories: map[string]bool{},
+			faults:       map[string][]*activeFault{},
+		}
+		// The server lives for as long as the test binary.
+		server := httptest.NewServer(testRegistry)
+		testRegistry.host = strings.TrimPrefix(server.URL, "http://")
+		testRegistryErr = testRegistry.seed()
+	})
+	if testRegistryErr != nil {
+		return "", testRegistryErr
+	}
+	return testRegistry.host, nil
+}
+
+// repositoryPath matches the repository name in registry API paths.
+var repositoryPath = regexp.MustCompile(`^/v2/(.+?)/(manifests|blobs|tags|referrers)/`)
+
+// repositoryRegistry is a registry that only serves repositories that were explicitly
+// created, like registries that do not create repositories on push (such as Amazon ECR).
+type repositoryRegistry struct {
+	host         string
+	inner        http.Handler
+	mu           sync.Mutex
+	repositories map[string]bool
+	faults       map[string][]*activeFault
+}
+
+func (r *repositoryRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	match := repositoryPath.FindStringSubmatch(req.URL.Path)
+	if match == nil {
+		r.inner.ServeHTTP(w, req)
+		return
+	}
+	if !r.exists(match[1]) {
+		writeRegistryError(w, http.StatusNotFound, fmt.Sprintf("repository %s does not exist", match[1]))
+		return
+	}
+	if fault := r.fault(match[1], req); fault != nil {
+		fault.serve(w, req, r.inner)
+		return
+	}
+	r.inner.ServeHTTP(w, req)
+}
+
+func (r *repositoryRegistry) create(repository string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.repositories[repository] = true
+}
+
+func (r *repositoryRegistry) delete(repository string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.repositories, repository)
+}
+
+func (r *repositoryRegistry) exists(repository string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.repositories[repository]
+}
+
+// seed pushes the source images to the registry.
+func (r *repositoryRegistry) seed() error {
+	for _, image := range sourceImages {
+		ref, err := name.NewTag(fmt.Sprintf("%s/%s", r.host, image))
+		if err != nil {
+			return err
+		}
+		r.create(ref.RepositoryStr())
+
+		idx, err := sourceIndex(image)
+		if err != nil {
+			return fmt.Errorf("building source image %s: %w", image, err)
+		}
+		if err := remote.WriteIndex(ref, idx); err != nil {
+			return fmt.Errorf("seeding source image %s: %w", image, err)
+		}
+	}
+	for _, image := range singlePlatformImages {
+		ref, err := name.NewTag(fmt.Sprintf("%s/%s", r.host, image))
+		if err != nil {
+			return err
+		}
+		r.create(ref.RepositoryStr())
+
+		img, err := sourceImage(image, sourcePlatforms[0])
+		if err != nil {
+			return fmt.Errorf("building source image %s: %w", image, err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			return fmt.Errorf("seeding source image %s: %w", image, err)
+		}
+	}
+	return nil
+}
+
+// sourceIndex builds a multi-platform image for image. The images are built
+// deterministically, so their digests are stable across test runs.
+func sourceIndex(image string) (v1.ImageIndex, error) {
+	idx := mutate.IndexMediaType(empty.Index, types.DockerManifestList)
+	for _, platform := range sourcePlatforms {
+		img, err := sourceImage(image, platform)
+		if err != nil {
+			return nil, err
+		}
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: &v1.Platform{OS: platform.OS, Architecture: platform.Architecture},
+			},
+		})
+	}
+	return idx, nil
+}
+
+// sourceImage builds the image of image for platform, deterministically.
+func sourceImage(image string, platform v1.Platform) (v1.Image, error) {
+	layer, err := contentLayer(fmt.Sprintf("%s %s", image, platform))
+	if err != nil {
+		return nil, err
+	}
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.DeepCopy()
+	cfg.OS = platform.OS
+	cfg.Architecture = platform.Architecture
+	return mutate.ConfigFile(img, cfg)
+}
+
+// contentLayer returns a layer holding a single file with content.
+func contentLayer(content string) (v1.Layer, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "content", Mode: 0o644, Size: int64(len(content))}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+}
@@ -3,24 +3,18 @@
 package testing
 
 import (
-	"context"
 	"fmt"
 	"math/rand"
-	"os"
-	"path"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ecr"
-	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
-var SOURCE_REGISTRY = os.Getenv("SOURCE_REGISTRY")
-
 const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
 
 func generateRandomString(length int) string {
@@ -32,74 +26,70 @@ func generateRandomString(length int) string {
 	return string(b)
 }
 
+// CreateSourceRef returns the reference of one of the seeded source images in the test
+// registry (e.g. `docker/library/alpine:latest`).
 func CreateSourceRef(image string) string {
-	return fmt.Sprintf("%s/%s", SOURCE_REGISTRY, image)
+	host, err := Registry()
+	if err != nil {
+		panic(fmt.Sprintf("failed to start test registry: %v", err))
+	}
+	return fmt.Sprintf("%s/%s", host, image)
 }
 
-func CreateRepository(t *testing.T) (string, func()) {
+// CreateSourceDigestRef returns the digest reference of one of the seeded source images in
+// the test registry.
+func CreateSourceDigestRef(t *testing.T, image string) string {
 	t.Helper()
 
-	// Load the AWS configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion("us-east-1"))
+	ref, err := name.ParseReference(CreateSourceRef(image))
 	if err != nil {
-		t.Fatalf("failed to load AWS configuration: %v", err)
+		t.Fatalf("failed to parse source image %s: %v", image, err)
 	}
+	digest, err := crane.Digest(ref.String())
+	if err != nil {
+		t.Fatalf("failed to read digest of source image %s: %v", image, err)
+	}
+	return ref.Context().Digest(digest).String()
+}
 
-	// Create an ECR client
-	svc := ecr.NewFromConfig(cfg)
+func CreateRepository(t *testing.T) (string, func()) {
+	t.Helper()
+
+	host, err := Registry()
+	if err != nil {
+		t.Fatalf("failed to start test registry: %v", err)
+	}
 
 	// Create a new repository
 	repoName := "test-repo-" + strings.ToLower(t.Name()) + "-" + generateRandomString(6)
 	t.Logf("Creating repository: %s", repoName)
-	input := &ecr.CreateRepositoryInput{
-		RepositoryName: aws.String(repoName),
-	}
+	testRegistry.create(repoName)
 
-	response, err := svc.CreateRepository(context.TODO(), input)
-	if err != nil {
-		t.Fatalf("failed to create repository: %v", err)
-	}
-
-	return *response.Repository.RepositoryUri, func() {
+	return fmt.Sprintf("%s/%s", host, repoName), func() {
 		// Cleanup: delete the repository after the test
-		deleteInput := &ecr.DeleteRepositoryInput{
-			RepositoryName: aws.String(repoName),
-			Force:          true,
-		}
-		_, err := svc.DeleteRepository(context.TODO(), deleteInput)
-		if err != nil {
-			t.Logf("failed to delete repository: %v", err)
-		}
+		testRegistry.delete(repoName)
 	}
 }
 
 func CreateLocalTarball(t *testing.T, imageRef string) string {
 	t.Helper()
 
-	tag := strings.Split(imageRef, ":")[1]
-	// Get the name by taking the part after the last slash and before the colon
-	nameParts := strings.Split(imageRef, "/")
-	name := nameParts[len(nameParts)-1]
-	name = strings.Split(name, ":")[0]
-
-	cwd, _ := os.Getwd()
-	index := strings.Index(cwd, "terraform-provider-crane")
-	root := cwd[:index+len("terraform-provider-crane")]
-	testingDir := path.Join(root, "testing")
-	tarPath := path.Join(testingDir, fmt.Sprintf("%s%s.tar.gz", name, tag))
-
-	if _, err := os.Stat(tarPath); os.IsNotExist(err) {
-		t.Logf("Creating local tarball: %s", tarPath)
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		t.Fatalf("failed to parse image %s: %v", imageRef, err)
+	}
+	repo := ref.Context().RepositoryStr()
+	tarPath := filepath.Join(t.TempDir(), fmt.Sprintf("%s%s.tar.gz", repo[strings.LastIndex(repo, "/")+1:], ref.Identifier()))
+	t.Logf("Creating local tarball: %s", tarPath)
 
-		img, err := crane.Pull(imageRef)
-		if err != nil {
-			t.Fatalf("failed to pull image %s: %v", imageRef, err)
-		}
+	img, err := crane.Pull(imageRef)
+	if err != nil {
+		t.Fatalf("failed to pull image %s: %v", imageRef, err)
+	}
 
-		err = crane.Save(img, imageRef, tarPath)
-		if err != nil {
-			t.Fatalf("failed to create tarball for image %s: %v", imageRef, err)
-		}
+	err = crane.Save(img, imageRef, tarPath)
+	if err != nil {
+		t.Fatalf("failed to create tarball for image %s: %v", imageRef, err)
 	}
 
 	return tarPath
@@ -111,7 +101,7 @@ func CopyImagesToRepository(t *testing.T, targetRepoUri string) (tags []string)
 	imageTags := []string{"latest", "alpine"}
 
 	for _, tag := range imageTags {
-		src := fmt.Sprintf("%s/nginx/nginx:%s", SOURCE_REGISTRY, tag)
+		src := CreateSourceRef(fmt.Sprintf("nginx/nginx:%s", tag))
 		dst := fmt.Sprintf("%s:%s", targetRepoUri, tag)
 
 		t.Logf("Copying image '%s' from '%s'", src, dst)
@@ -126,25 +116,13 @@ func CopyImagesToRepository(t *testing.T, targetRepoUri string) (tags []string)
 func DeleteRemoteImage(t *testing.T, repoUri string, tag string) {
 	t.Helper()
 
-	// Load the AWS configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion("us-east-1"))
+	ref, err := name.NewTag(fmt.Sprintf("%s:%s", repoUri, tag))
 	if err != nil {
-		t.Fatalf("failed to load AWS configuration: %v", err)
+		t.Fatalf("failed to parse image %s:%s: %v", repoUri, tag, err)
 	}
 
-	// Create an ECR client
-	svc := ecr.NewFromConfig(cfg)
-
 	// Delete the image from the repository
-	input := &ecr.BatchDeleteImageInput{
-		RepositoryName: aws.String(strings.Split(repoUri, "/")[1]),
-		ImageIds: []types.ImageIdentifier{
-			{ImageTag: aws.String(tag)},
-		},
-	}
-
-	_, err = svc.BatchDeleteImage(context.TODO(), input)
-	if err != nil {
+	if err := remote.Delete(ref); err != nil {
 		t.Fatalf("failed to delete image %s from repository %s: %v", tag, repoUri, err)
 	}
 }